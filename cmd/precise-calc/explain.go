@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"precise-calc/pkg/calculator"
+)
+
+// explain evaluates the expression and writes how the result was derived.
+// When evaluation fails, the text output still shows the steps that
+// succeeded before the error.
+func explain(opts *options) {
+	explanation, err := calculator.ExplainWithOptions(opts.expression, opts.calc)
+	if err != nil {
+		if explanation != nil && !opts.json {
+			writeExplanation(os.Stdout, explanation, "")
+		}
+		fail(err, opts)
+	}
+
+	output, err := render(explanation.Result, explanation.Tokens, opts)
+	if err != nil {
+		fail(err, opts)
+	}
+	if opts.json {
+		if err := writeJSONExplanation(os.Stdout, explanation, output); err != nil {
+			fail(err, opts)
+		}
+		return
+	}
+	writeExplanation(os.Stdout, explanation, output)
+}
+
+// writeExplanation writes the tokens, postfix order, parenthesized form and
+// numbered steps of an evaluation, followed by the result unless it is empty
+func writeExplanation(w io.Writer, explanation *calculator.Explanation, output string) {
+	tokens := make([]string, len(explanation.Tokens))
+	for i, token := range explanation.Tokens {
+		tokens[i] = "[" + token.Value + "]"
+	}
+	postfix := make([]string, len(explanation.Postfix))
+	for i, token := range explanation.Postfix {
+		postfix[i] = token.Value
+		if token.Type == calculator.FunctionToken {
+			postfix[i] = fmt.Sprintf("%s/%d", token.Value, token.Args)
+		}
+	}
+
+	fmt.Fprintf(w, "Expression:    %s\n", explanation.Expression)
+	fmt.Fprintf(w, "Tokens:        %s\n", strings.Join(tokens, " "))
+	fmt.Fprintf(w, "Postfix:       %s\n", strings.Join(postfix, " "))
+	fmt.Fprintf(w, "Parenthesized: %s\n", explanation.Parenthesized)
+	fmt.Fprintf(w, "Steps:\n")
+	if len(explanation.Steps) == 0 {
+		fmt.Fprintf(w, "  (none)\n")
+	}
+	for i, step := range explanation.Steps {
+		fmt.Fprintf(w, "  %d. %s\n", i+1, step)
+	}
+	if output != "" {
+		fmt.Fprintf(w, "Result:        %s\n", output)
+	}
+}
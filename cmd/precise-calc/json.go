@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"math/big"
+	"reflect"
+
+	"precise-calc/pkg/calculator"
+)
+
+// jsonResult is the --json output for a successful calculation. The
+// numerator and denominator are strings so that arbitrarily large values
+// survive JSON parsers that read numbers as floats.
+type jsonResult struct {
+	Expression  string `json:"expression"`
+	Result      string `json:"result"`
+	Numerator   string `json:"numerator"`
+	Denominator string `json:"denominator"`
+	Decimal     string `json:"decimal"`
+	Hex         string `json:"hex"`
+	IsInteger   bool   `json:"is_integer"`
+}
+
+// jsonFailure is the --json output for a failed calculation. With
+// --all-errors, Errors lists every problem and Error repeats the first.
+type jsonFailure struct {
+	Expression string      `json:"expression"`
+	Error      jsonError   `json:"error"`
+	Errors     []jsonError `json:"errors,omitempty"`
+}
+
+// jsonError describes a calculator error. Position, end, line, column and
+// context are omitted for errors that are not tied to a place in the
+// expression. Position and end count runes.
+type jsonError struct {
+	Type       string `json:"type"`
+	Code       string `json:"code,omitempty"`
+	Message    string `json:"message"`
+	Position   *int   `json:"position,omitempty"`
+	End        *int   `json:"end,omitempty"`
+	Line       int    `json:"line,omitempty"`
+	Column     int    `json:"column,omitempty"`
+	Context    string `json:"context,omitempty"`
+	Character  string `json:"character,omitempty"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// jsonExplanation is the --explain --json output. Values are exact and
+// written as strings, as in jsonResult; Result is formatted by the output
+// flags.
+type jsonExplanation struct {
+	Expression    string      `json:"expression"`
+	Tokens        []jsonToken `json:"tokens"`
+	Postfix       []string    `json:"postfix"`
+	Parenthesized string      `json:"parenthesized"`
+	Steps         []jsonStep  `json:"steps"`
+	Result        string      `json:"result"`
+	Exact         string      `json:"exact"`
+}
+
+// jsonToken describes a token of the expression. Position counts runes.
+type jsonToken struct {
+	Type     string `json:"type"`
+	Value    string `json:"value"`
+	Position int    `json:"position"`
+}
+
+// jsonStep describes one operation of the evaluation
+type jsonStep struct {
+	Operator string   `json:"operator"`
+	Position int      `json:"position"`
+	Operands []string `json:"operands"`
+	Result   string   `json:"result"`
+}
+
+// writeJSONResult writes a successful calculation as JSON
+func writeJSONResult(w io.Writer, expression string, result *big.Rat, output string) error {
+	decimal, err := calculator.FormatRadixWithOptions(result, calculator.RadixOptions{Base: 10})
+	if err != nil {
+		return err
+	}
+	hex, err := calculator.FormatRadix(result, 16)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, jsonResult{
+		Expression:  expression,
+		Result:      output,
+		Numerator:   result.Num().String(),
+		Denominator: result.Denom().String(),
+		Decimal:     decimal,
+		Hex:         hex,
+		IsInteger:   result.IsInt(),
+	})
+}
+
+// writeJSONExplanation writes an explained calculation as JSON
+func writeJSONExplanation(w io.Writer, explanation *calculator.Explanation, output string) error {
+	described := jsonExplanation{
+		Expression:    explanation.Expression,
+		Tokens:        []jsonToken{},
+		Postfix:       []string{},
+		Parenthesized: explanation.Parenthesized,
+		Steps:         []jsonStep{},
+		Result:        output,
+		Exact:         calculator.NewRational(explanation.Result).String(),
+	}
+	for _, token := range explanation.Tokens {
+		described.Tokens = append(described.Tokens, jsonToken{Type: token.Type.String(), Value: token.Value, Position: token.Position})
+	}
+	for _, token := range explanation.Postfix {
+		described.Postfix = append(described.Postfix, token.Value)
+	}
+	for _, step := range explanation.Steps {
+		operands := make([]string, len(step.Operands))
+		for i, operand := range step.Operands {
+			if operand.IsText() {
+				operands[i] = operand.Text
+			} else {
+				operands[i] = calculator.NewRational(operand.Value).String()
+			}
+		}
+		described.Steps = append(described.Steps, jsonStep{
+			Operator: step.Token.Value,
+			Position: step.Token.Position,
+			Operands: operands,
+			Result:   calculator.NewRational(step.Result).String(),
+		})
+	}
+	return writeJSON(w, described)
+}
+
+// writeJSONError writes a failed calculation as JSON
+func writeJSONError(w io.Writer, expression string, err error) error {
+	failure := jsonFailure{Expression: expression}
+	if list, ok := err.(calculator.ErrorList); ok && len(list) > 0 {
+		for _, err := range list {
+			failure.Errors = append(failure.Errors, describeError(err))
+		}
+		failure.Error = failure.Errors[0]
+	} else {
+		failure.Error = describeError(err)
+	}
+	return writeJSON(w, failure)
+}
+
+// writeJSONUsageError writes an invalid flag or argument as a jsonFailure, so
+// that --json output can be parsed even when the arguments are rejected
+func writeJSONUsageError(w io.Writer, err error) error {
+	return writeJSON(w, jsonFailure{Error: jsonError{Type: "UsageError", Message: err.Error()}})
+}
+
+// describeError converts a calculator error into its JSON form
+func describeError(err error) jsonError {
+	described := jsonError{
+		Type:       reflect.TypeOf(err).Name(),
+		Code:       string(calculator.CodeOf(err)),
+		Suggestion: calculator.SuggestionOf(err),
+		Message:    err.Error(),
+	}
+
+	switch e := err.(type) {
+	case calculator.ParseError:
+		described.Message = e.Message
+	case calculator.InvalidCharacterError:
+		described.Character = string(e.Character)
+	}
+
+	if source, ok := err.(calculator.SourceError); ok {
+		if start, end := source.Span(); start >= 0 {
+			described.Position = &start
+			described.End = &end
+			described.Line = source.Where().Line
+			described.Column = source.Where().Column
+			described.Context = source.Snippet()
+		}
+	}
+
+	if described.Type == "" {
+		described.Type = "Error"
+	}
+	return described
+}
+
+// writeJSON encodes v as indented JSON followed by a newline
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(v)
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"math/big"
 	"os"
 	"strconv"
@@ -10,47 +12,205 @@ import (
 	"precise-calc/pkg/calculator"
 )
 
+// Exit codes for each category of error
+const (
+	exitSyntax        = 1 // the expression is malformed
+	exitUsage         = 2 // invalid flags or arguments
+	exitArithmetic    = 3 // evaluation failed, e.g. division by zero
+	exitConfiguration = 4 // invalid output or precision settings
+	exitLimit         = 5 // the expression exceeds the --safe limits
+)
+
 func main() {
 	// Check command line arguments
 	if len(os.Args) < 2 {
-		fmt.Fprintf(os.Stderr, "Usage: %s \"<expression>\"\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "Example: %s \"0.1 + 0.2\"\n", os.Args[0])
-		os.Exit(1)
+		printUsage(os.Stderr, os.Args[0])
+		os.Exit(exitUsage)
 	}
 
-	// Get the expression from command line arguments
-	expression := os.Args[1]
-
-	// Calculate the result
-	result, err := calculator.Calculate(expression)
+	opts, err := parseArgs(os.Args[1:])
 	if err != nil {
-		handleError(err)
-		os.Exit(1)
+		if wantsJSON(os.Args[1:]) {
+			writeJSONUsageError(os.Stdout, err)
+			os.Exit(exitUsage)
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		printUsage(os.Stderr, os.Args[0])
+		os.Exit(exitUsage)
+	}
+
+	if opts.allErrors {
+		if err := calculator.ValidateAll(opts.expression, opts.calc); err != nil {
+			fail(err, opts)
+		}
+	}
+
+	if opts.explain {
+		explain(opts)
+		return
 	}
 
-	// Format and output the result
-	output := formatOutput(result)
+	// Calculate the result and format it for output
+	result, output, err := compute(opts)
+	if err != nil {
+		fail(err, opts)
+	}
+	if opts.json {
+		if err := writeJSONResult(os.Stdout, opts.expression, result, output); err != nil {
+			fail(err, opts)
+		}
+		return
+	}
 	fmt.Println(output)
 }
 
+// compute evaluates the expression in the requested mode and formats the result
+func compute(opts *options) (*big.Rat, string, error) {
+	modes, err := calculator.New(calculator.WithLimits(opts.calc.Limits))
+	if err != nil {
+		return nil, "", err
+	}
+	if opts.trackSigFigs {
+		value, err := modes.CalculateSignificant(opts.expression)
+		if err != nil {
+			return nil, "", err
+		}
+		return value.Value, value.String(), nil
+	}
+	if opts.numeric != nil {
+		value, err := modes.CalculateFixed(opts.expression, *opts.numeric)
+		if err != nil {
+			return nil, "", err
+		}
+		return value.Rat(), value.String(), nil
+	}
+	if opts.decimal {
+		value, err := modes.CalculateDecimal(opts.expression, opts.division)
+		if err != nil {
+			return nil, "", err
+		}
+		return value.Rat(), value.String(), nil
+	}
+
+	expr, err := calculator.CalculateExpressionWithOptions(opts.expression, opts.calc)
+	if err != nil {
+		return nil, "", err
+	}
+	output, err := render(expr.Result, expr.Tokens, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return expr.Result, output, nil
+}
+
+// fail reports an error in the requested output format and exits
+func fail(err error, opts *options) {
+	if opts.json {
+		writeJSONError(os.Stdout, opts.expression, err)
+	} else {
+		var list calculator.ErrorList
+		if !errors.As(err, &list) {
+			list = calculator.ErrorList{err}
+		}
+		for _, err := range list {
+			handleError(err)
+			printSnippet(os.Stderr, opts.expression, err, opts.color)
+			if hint := calculator.SuggestionOf(err); hint != "" {
+				fmt.Fprintf(os.Stderr, "Hint: %s\n", hint)
+			}
+		}
+	}
+	os.Exit(exitCode(err))
+}
+
+// exitCode returns the process exit code for an error's category
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, calculator.ErrArithmetic):
+		return exitArithmetic
+	case errors.Is(err, calculator.ErrConfiguration):
+		return exitConfiguration
+	case errors.Is(err, calculator.ErrLimit):
+		return exitLimit
+	}
+	return exitSyntax
+}
+
+// printSnippet shows the expression with the error position underlined,
+// coloring the underline when requested. Multi-line expressions also get the
+// line and column, since positions count runes across all lines.
+func printSnippet(w io.Writer, expression string, err error, color bool) {
+	var source calculator.SourceError
+	if !errors.As(err, &source) || source.Snippet() == "" {
+		return
+	}
+
+	if strings.Contains(expression, "\n") {
+		loc := source.Where()
+		fmt.Fprintf(w, "  --> line %d, column %d\n", loc.Line, loc.Column)
+	}
+
+	lines := strings.Split(source.Snippet(), "\n")
+	for i, line := range lines {
+		if color && i == len(lines)-1 {
+			line = "\x1b[1;31m" + line + "\x1b[0m"
+		}
+		fmt.Fprintf(w, "  %s\n", line)
+	}
+}
+
+// render formats the result according to the requested output options. The
+// tokens the result was computed from choose the base for --base auto.
+func render(result *big.Rat, tokens []calculator.Token, opts *options) (string, error) {
+	if opts.pattern != "" || opts.locale != "" {
+		loc, _ := calculator.LookupLocale(opts.locale)
+		if opts.pattern == "" {
+			return calculator.FormatLocale(result, loc)
+		}
+		return calculator.FormatPattern(result, opts.pattern, loc)
+	}
+	switch opts.fraction {
+	case "mixed":
+		return calculator.FormatMixed(result), nil
+	case "unicode":
+		return calculator.FormatUnicodeFraction(result), nil
+	}
+	if opts.sigFigs != 0 {
+		return calculator.FormatSignificant(result, opts.sigFigs), nil
+	}
+	switch opts.notation {
+	case "sci":
+		return calculator.FormatScientific(result, opts.digits), nil
+	case "eng":
+		return calculator.FormatEngineering(result, opts.digits), nil
+	}
+	if opts.autoBase {
+		if base := calculator.AutoBase(result, tokens); base != 10 {
+			return calculator.FormatRadix(result, base)
+		}
+		return formatOutput(result), nil
+	}
+	if opts.base != 0 {
+		return calculator.FormatRadixWithOptions(result, calculator.RadixOptions{
+			Base:   opts.base,
+			Prefix: !opts.noPrefix,
+		})
+	}
+	return formatOutput(result), nil
+}
+
 // handleError formats and outputs error messages
 func handleError(err error) {
-	switch e := err.(type) {
-	case calculator.DivisionByZeroError:
-		fmt.Fprintf(os.Stderr, "Error: Division by zero\n")
-	case calculator.InvalidCharacterError:
-		fmt.Fprintf(os.Stderr, "Error: Invalid character '%c' at position %d\n", e.Character, e.Position)
-	case calculator.ParseError:
-		if e.Position >= 0 {
-			fmt.Fprintf(os.Stderr, "Error: %s at position %d\n", e.Message, e.Position)
+	var parseErr calculator.ParseError
+	if errors.As(err, &parseErr) {
+		if parseErr.Position >= 0 {
+			fmt.Fprintf(os.Stderr, "Error: %s at position %d\n", parseErr.Message, parseErr.Position)
 		} else {
-			fmt.Fprintf(os.Stderr, "Error: %s\n", e.Message)
+			fmt.Fprintf(os.Stderr, "Error: %s\n", parseErr.Message)
 		}
-	case calculator.EmptyExpressionError:
-		fmt.Fprintf(os.Stderr, "Error: Empty expression provided\n")
-	default:
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return
 	}
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 }
 
 // formatOutput formats the result for display
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"precise-calc/pkg/calculator"
+)
+
+// options holds the parsed command line
+type options struct {
+	expression   string
+	base         int
+	autoBase     bool
+	noPrefix     bool
+	notation     string
+	digits       int
+	pattern      string
+	locale       string
+	fraction     string
+	json         bool
+	color        bool
+	allErrors    bool
+	explain      bool
+	sigFigs      int
+	trackSigFigs bool
+	decimal      bool
+	division     calculator.DivisionContext
+	numeric      *calculator.FixedDecimal
+	calc         calculator.Options
+}
+
+// defaultDigits is the number of significant digits used by --sci and --eng
+const defaultDigits = 10
+
+// wantsJSON reports whether the arguments ask for --json output, for
+// reporting errors in arguments that parseArgs rejects
+func wantsJSON(args []string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "json" {
+			continue
+		}
+		if !hasValue {
+			return true
+		}
+		on, err := strconv.ParseBool(value)
+		return err == nil && on
+	}
+	return false
+}
+
+// parseArgs parses flags and the expression argument. Flags may appear before
+// or after the expression, and an expression starting with a negative number
+// such as "-5 + 3" is never mistaken for a flag.
+func parseArgs(args []string) (*options, error) {
+	opts := &options{}
+
+	fs := flag.NewFlagSet("precise-calc", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	baseFlag := fs.String("base", "", "output base (2-36 or auto)")
+	hex := fs.Bool("hex", false, "output in hexadecimal")
+	oct := fs.Bool("oct", false, "output in octal")
+	bin := fs.Bool("bin", false, "output in binary")
+	fs.BoolVar(&opts.noPrefix, "no-prefix", false, "omit the 0x/0o/0b prefix")
+	sci := fs.Bool("sci", false, "output in scientific notation")
+	eng := fs.Bool("eng", false, "output in engineering notation")
+	fs.IntVar(&opts.digits, "digits", defaultDigits, "significant digits for --sci and --eng")
+	fs.StringVar(&opts.pattern, "format", "", "number format pattern, e.g. #,##0.00")
+	fs.StringVar(&opts.locale, "locale", "", "locale for separators, e.g. de-DE")
+	fs.StringVar(&opts.fraction, "fraction", "", "fraction style: mixed or unicode")
+	fs.IntVar(&opts.sigFigs, "sig-figs", 0, "round to N significant figures")
+	fs.BoolVar(&opts.trackSigFigs, "track-sig-figs", false, "propagate significant figures from the literals")
+	fs.BoolVar(&opts.decimal, "decimal", false, "keep the scale of decimal literals, e.g. 1.10 + 2.20 = 3.30")
+	fs.IntVar(&opts.division.Scale, "scale", 0, "minimum scale of inexact quotients in --decimal mode")
+	numeric := fs.String("numeric", "", "evaluate as SQL NUMERIC(P,S), given as P,S")
+	rounding := fs.String("rounding", "half-up", "rounding mode for inexact results")
+	fs.BoolVar(&opts.json, "json", false, "write the result or error as JSON")
+	fs.BoolVar(&opts.color, "color", false, "highlight the error position in color")
+	fs.BoolVar(&opts.allErrors, "all-errors", false, "report every problem in the expression, not just the first")
+	fs.BoolVar(&opts.explain, "explain", false, "show the tokens, precedence and each step of the evaluation")
+	safe := fs.Bool("safe", false, "apply the default resource limits for untrusted input")
+	fs.BoolVar(&opts.calc.MixedNumbers, "mixed-input", false, "read \"2 1/3\" as a single mixed number")
+
+	// Separate flags from the expression before parsing so that an expression
+	// after the flags is never consumed as an unknown flag
+	flagArgs := []string{}
+	positional := []string{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			positional = append(positional, args[i+1:]...)
+			break
+		}
+		if isExpressionArg(arg) {
+			positional = append(positional, arg)
+			continue
+		}
+		flagArgs = append(flagArgs, arg)
+		if takesValue(fs, arg) && i+1 < len(args) {
+			i++
+			flagArgs = append(flagArgs, args[i])
+		}
+	}
+	if err := fs.Parse(flagArgs); err != nil {
+		return nil, err
+	}
+
+	if len(positional) == 0 {
+		return nil, errors.New("missing expression")
+	}
+	opts.expression = positional[0]
+
+	if *baseFlag == "auto" {
+		opts.autoBase = true
+	} else if *baseFlag != "" {
+		base, err := strconv.Atoi(*baseFlag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base %q: must be a number between 2 and 36 or auto", *baseFlag)
+		}
+		opts.base = base
+	}
+
+	// Only one output style may be selected
+	styles := []string{}
+	if opts.base != 0 || opts.autoBase {
+		styles = append(styles, "--base")
+	}
+	if *hex {
+		opts.base = 16
+		styles = append(styles, "--hex")
+	}
+	if *oct {
+		opts.base = 8
+		styles = append(styles, "--oct")
+	}
+	if *bin {
+		opts.base = 2
+		styles = append(styles, "--bin")
+	}
+	if *sci {
+		opts.notation = "sci"
+		styles = append(styles, "--sci")
+	}
+	if *eng {
+		opts.notation = "eng"
+		styles = append(styles, "--eng")
+	}
+	if opts.pattern != "" || opts.locale != "" {
+		styles = append(styles, "--format/--locale")
+	}
+	if opts.fraction != "" {
+		if opts.fraction != "mixed" && opts.fraction != "unicode" {
+			return nil, fmt.Errorf("invalid fraction style %q: must be mixed or unicode", opts.fraction)
+		}
+		styles = append(styles, "--fraction")
+	}
+	if opts.sigFigs != 0 {
+		styles = append(styles, "--sig-figs")
+	}
+	if opts.trackSigFigs {
+		styles = append(styles, "--track-sig-figs")
+	}
+	if opts.decimal {
+		styles = append(styles, "--decimal")
+	}
+	if *numeric != "" {
+		styles = append(styles, "--numeric")
+	}
+	if len(styles) > 1 {
+		return nil, fmt.Errorf("conflicting output flags: %s", strings.Join(styles, ", "))
+	}
+
+	if opts.base != 0 && (opts.base < 2 || opts.base > 36) {
+		return nil, fmt.Errorf("invalid base %d: must be between 2 and 36", opts.base)
+	}
+	if opts.locale != "" {
+		if _, ok := calculator.LookupLocale(opts.locale); !ok {
+			return nil, fmt.Errorf("unknown locale %q: available locales are %s",
+				opts.locale, strings.Join(calculator.LocaleNames(), ", "))
+		}
+	}
+	if opts.sigFigs < 0 {
+		return nil, fmt.Errorf("invalid significant figures %d: must be at least 1", opts.sigFigs)
+	}
+	if (opts.trackSigFigs || opts.decimal || *numeric != "") && opts.calc.MixedNumbers {
+		return nil, errors.New("--mixed-input cannot be combined with --track-sig-figs, --decimal or --numeric")
+	}
+	if (opts.trackSigFigs || opts.decimal || *numeric != "") && opts.explain {
+		return nil, errors.New("--explain cannot be combined with --track-sig-figs, --decimal or --numeric")
+	}
+	if *safe {
+		opts.calc.Limits = calculator.DefaultLimits
+	}
+	if opts.division.Scale < 0 {
+		return nil, fmt.Errorf("invalid scale %d: must not be negative", opts.division.Scale)
+	}
+	mode, err := calculator.ParseRoundingMode(*rounding)
+	if err != nil {
+		return nil, err
+	}
+	opts.division.Rounding = mode
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "scale" || f.Name == "rounding" {
+			opts.division.Set = true
+		}
+	})
+	if *numeric != "" {
+		fixed, err := parseNumeric(*numeric)
+		if err != nil {
+			return nil, err
+		}
+		fixed.Rounding = mode
+		opts.numeric = &fixed
+	}
+	if opts.digits < 1 {
+		return nil, fmt.Errorf("invalid digits %d: must be at least 1", opts.digits)
+	}
+
+	return opts, nil
+}
+
+// parseNumeric parses a "P,S" precision and scale pair
+func parseNumeric(spec string) (calculator.FixedDecimal, error) {
+	p, s, found := strings.Cut(spec, ",")
+	precision, perr := strconv.Atoi(strings.TrimSpace(p))
+	scale, serr := strconv.Atoi(strings.TrimSpace(s))
+	if !found || perr != nil || serr != nil {
+		return calculator.FixedDecimal{}, fmt.Errorf("invalid numeric type %q: must be P,S such as 18,2", spec)
+	}
+	return calculator.NewFixedDecimal(precision, scale)
+}
+
+// isExpressionArg reports whether an argument is part of the expression rather than a flag
+func isExpressionArg(arg string) bool {
+	if len(arg) < 2 || arg[0] != '-' {
+		return true
+	}
+	next := arg[1]
+	return (next >= '0' && next <= '9') || next == '.' || next == ' '
+}
+
+// takesValue reports whether a flag argument without "=" consumes the next argument
+func takesValue(fs *flag.FlagSet, arg string) bool {
+	name := strings.TrimLeft(arg, "-")
+	if strings.Contains(name, "=") {
+		return false
+	}
+	f := fs.Lookup(name)
+	if f == nil {
+		return false
+	}
+	if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+		return false
+	}
+	return true
+}
+
+// printUsage writes the usage message to w
+func printUsage(w io.Writer, program string) {
+	fmt.Fprintf(w, "Usage: %s [flags] \"<expression>\"\n", program)
+	fmt.Fprintf(w, "Example: %s \"0.1 + 0.2\"\n", program)
+	fmt.Fprintf(w, "\nFlags:\n")
+	fmt.Fprintf(w, "  --base N      output in base N (2-36)\n")
+	fmt.Fprintf(w, "  --base auto   output in the base of the input literals when they all agree\n")
+	fmt.Fprintf(w, "  --hex         output in hexadecimal (same as --base 16)\n")
+	fmt.Fprintf(w, "  --oct         output in octal (same as --base 8)\n")
+	fmt.Fprintf(w, "  --bin         output in binary (same as --base 2)\n")
+	fmt.Fprintf(w, "  --no-prefix   omit the 0x/0o/0b prefix\n")
+	fmt.Fprintf(w, "  --sci         output in scientific notation, e.g. 1.234e-40\n")
+	fmt.Fprintf(w, "  --eng         output in engineering notation (exponent a multiple of 3)\n")
+	fmt.Fprintf(w, "  --digits N    significant digits for --sci and --eng (default %d)\n", defaultDigits)
+	fmt.Fprintf(w, "  --format P    format with a pattern such as \"#,##0.00;(#,##0.00)\"\n")
+	fmt.Fprintf(w, "  --locale L    use the separators and default pattern of locale L, e.g. de-DE\n")
+	fmt.Fprintf(w, "  --fraction S  show fractions as mixed numbers (mixed: 2 1/3, unicode: 2⅓)\n")
+	fmt.Fprintf(w, "  --sig-figs N  round the result to N significant figures\n")
+	fmt.Fprintf(w, "  --track-sig-figs\n")
+	fmt.Fprintf(w, "                report the result to the significant figures of the inputs\n")
+	fmt.Fprintf(w, "  --decimal     keep the scale of decimal literals, e.g. 1.10 + 2.20 = 3.30\n")
+	fmt.Fprintf(w, "  --scale N     minimum scale of inexact quotients in --decimal mode; without\n")
+	fmt.Fprintf(w, "                --scale or --rounding, inexact division is an error\n")
+	fmt.Fprintf(w, "  --numeric P,S evaluate as SQL NUMERIC(P,S), rounding after every operation\n")
+	fmt.Fprintf(w, "  --rounding M  rounding mode: half-up (default), half-even, half-down, up,\n")
+	fmt.Fprintf(w, "                down, ceiling, floor or unnecessary\n")
+	fmt.Fprintf(w, "  --json        write the result or error as a JSON object on stdout\n")
+	fmt.Fprintf(w, "  --color       highlight the position of an error in color\n")
+	fmt.Fprintf(w, "  --explain     show the tokens, the parenthesized expression and each step,\n")
+	fmt.Fprintf(w, "                as text or with --json as JSON\n")
+	fmt.Fprintf(w, "  --all-errors  report every problem in the expression, not just the first\n")
+	fmt.Fprintf(w, "  --safe        limit input size, number size and work for untrusted input\n")
+	fmt.Fprintf(w, "  --mixed-input read \"2 1/3\" in the expression as a single mixed number\n")
+}
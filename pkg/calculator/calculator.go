@@ -1,6 +1,7 @@
 package calculator
 
 import (
+	"context"
 	"math/big"
 	"strconv"
 	"strings"
@@ -8,23 +9,68 @@ import (
 
 // Calculate evaluates a mathematical expression and returns the exact result
 func Calculate(expression string) (*big.Rat, error) {
+	return CalculateWithOptions(expression, Options{})
+}
+
+// CalculateWithOptions evaluates a mathematical expression, accepting the
+// optional syntax enabled in opts
+func CalculateWithOptions(expression string, opts Options) (*big.Rat, error) {
+	return CalculateContextWithOptions(context.Background(), expression, opts)
+}
+
+// CalculateContext evaluates an expression like Calculate, checking ctx between
+// operations. Once ctx is canceled or its deadline passes, evaluation stops
+// with a CanceledError wrapping ctx.Err(), so errors.Is(err,
+// context.DeadlineExceeded) reports a timeout. A single big.Rat operation
+// cannot be interrupted; Options.Limits bounds how large its operands get.
+func CalculateContext(ctx context.Context, expression string) (*big.Rat, error) {
+	return CalculateContextWithOptions(ctx, expression, Options{})
+}
+
+// CalculateContextWithOptions evaluates an expression like CalculateWithOptions,
+// stopping early when ctx is done
+func CalculateContextWithOptions(ctx context.Context, expression string, opts Options) (*big.Rat, error) {
+	return defaultCalculator.withOptions(opts).CalculateContext(ctx, expression)
+}
+
+// CalculateExpression evaluates an expression like Calculate, returning the
+// full record of what was evaluated: the original text, its tokens in infix
+// and postfix order, the number literals and the result. When evaluation
+// fails after parsing, the Expression is returned with a nil Result
+// alongside the error.
+func CalculateExpression(expression string) (*Expression, error) {
+	return defaultCalculator.CalculateExpression(expression)
+}
+
+// CalculateExpressionWithOptions evaluates an expression like
+// CalculateExpression, accepting the optional syntax enabled in opts
+func CalculateExpressionWithOptions(expression string, opts Options) (*Expression, error) {
+	return defaultCalculator.withOptions(opts).CalculateExpression(expression)
+}
+
+// parseInput tokenizes and parses an expression ready for evaluation,
+// reporting each stage to the observer
+func (c *Calculator) parseInput(expression string) (*Expression, error) {
 	// Store original for error reporting
 	original := expression
 
-	// Trim whitespace
-	expression = strings.TrimSpace(expression)
-	if expression == "" {
-		return nil, EmptyExpressionError{}
+	tokens, err := c.tokenizeInput(expression)
+	if c.observer != nil {
+		c.observer.Tokenized(expression, tokens, withSource(err, expression))
 	}
-
-	// Tokenize the expression
-	tokens, err := Tokenize(expression)
 	if err != nil {
 		return nil, err
 	}
 
 	// Parse and validate expression structure
-	expr, err := ParseExpression(tokens)
+	expr, err := c.parseTokens(tokens)
+	if c.observer != nil {
+		var postfix []Token
+		if expr != nil {
+			postfix = expr.PostfixTokens
+		}
+		c.observer.Parsed(expression, postfix, withSource(err, expression))
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -32,16 +78,27 @@ func Calculate(expression string) (*big.Rat, error) {
 	// Store original in expression
 	expr.Original = original
 
-	// Evaluate the postfix expression
-	result, err := EvaluatePostfix(expr.PostfixTokens)
-	if err != nil {
+	return expr, nil
+}
+
+// tokenizeInput tokenizes an expression within the calculator's limits
+func (c *Calculator) tokenizeInput(expression string) ([]Token, error) {
+	// Positions stay relative to the untrimmed input, so only check for blanks
+	if strings.TrimSpace(expression) == "" {
+		return nil, EmptyExpressionError{}
+	}
+	if err := c.limits.checkLength(expression); err != nil {
 		return nil, err
 	}
 
-	// Store result in expression
-	expr.Result = result
-
-	return result, nil
+	tokens, err := c.tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.limits.checkTokens(tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
 }
 
 // FormatResult formats calculation result for display
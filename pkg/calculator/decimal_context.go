@@ -0,0 +1,216 @@
+package calculator
+
+import (
+	"context"
+	"math/big"
+	"strings"
+)
+
+// Signal is a set of exceptional conditions raised during evaluation in a
+// Context, modelled on the signals of Python's decimal module
+type Signal uint
+
+const (
+	// SignalInexact is raised when rounding discards non-zero digits
+	SignalInexact Signal = 1 << iota
+	// SignalRounded is raised when a result is rounded; since values are
+	// exact rationals without trailing zeros it accompanies SignalInexact
+	SignalRounded
+	// SignalSubnormal is raised when a non-zero result is below 10^Emin
+	SignalSubnormal
+	// SignalUnderflow is raised when a subnormal result is also inexact
+	SignalUnderflow
+	// SignalOverflow is raised when a result is 10^(Emax+1) or more in magnitude
+	SignalOverflow
+	// SignalDivisionByZero is raised when a non-zero value is divided by zero
+	SignalDivisionByZero
+	// SignalInvalidOperation is raised for operations with no defined result,
+	// such as zero divided by zero
+	SignalInvalidOperation
+)
+
+// signalNames lists signal names in bit order
+var signalNames = []string{"Inexact", "Rounded", "Subnormal", "Underflow", "Overflow", "DivisionByZero", "InvalidOperation"}
+
+// String returns the names of the signals in the set joined by "|", e.g.
+// "Inexact|Rounded", or "0" for the empty set
+func (s Signal) String() string {
+	var names []string
+	for i, name := range signalNames {
+		if s&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "0"
+	}
+	return strings.Join(names, "|")
+}
+
+// Has reports whether all signals in other are in the set
+func (s Signal) Has(other Signal) bool {
+	return s&other == other
+}
+
+// Context is an arithmetic context in the manner of Python's decimal module.
+// Each operation result is rounded to Precision significant digits with
+// Rounding, and checked against the exponent limits Emin and Emax. Every
+// condition met along the way is recorded as a Signal; signals in Traps stop
+// evaluation with an error, the rest are only reported in the result flags.
+//
+// A Precision of zero keeps results exact, and an Emin or Emax of zero leaves
+// that side of the exponent range unbounded, so the zero Context evaluates
+// exactly like Calculate except that division by zero is reported as a flag.
+type Context struct {
+	Precision int
+	Rounding  RoundingMode
+	Emin      int
+	Emax      int
+	Traps     Signal
+}
+
+// DefaultContext matches the defaults of Python's decimal module: 28 digits,
+// half-even rounding, and traps on invalid operations, division by zero and
+// overflow
+var DefaultContext = Context{
+	Precision: 28,
+	Rounding:  RoundHalfEven,
+	Emin:      -999999,
+	Emax:      999999,
+	Traps:     SignalInvalidOperation | SignalDivisionByZero | SignalOverflow,
+}
+
+// ContextResult is the outcome of evaluating an expression in a Context.
+// Value is nil when an untrapped overflow, division by zero or invalid
+// operation left the expression without a finite value; such a value
+// propagates through later operations without raising further signals.
+type ContextResult struct {
+	Value *big.Rat
+	Flags Signal
+}
+
+// Calculate evaluates an expression in the context. A trapped division by
+// zero returns a DivisionByZeroError; any other trapped signal returns a
+// SignalError at the position of the operator that raised it.
+func (c Context) Calculate(expression string) (ContextResult, error) {
+	return defaultCalculator.CalculateIn(expression, c)
+}
+
+// CalculateIn evaluates an expression in the arithmetic context dc, like
+// Context.Calculate, with the calculator's limits and observer
+func (c *Calculator) CalculateIn(expression string, dc Context) (ContextResult, error) {
+	return c.CalculateInContext(context.Background(), expression, dc)
+}
+
+// CalculateInContext evaluates an expression like CalculateIn, stopping with
+// a CanceledError once ctx is done
+func (c *Calculator) CalculateInContext(ctx context.Context, expression string, dc Context) (ContextResult, error) {
+	arith := &contextArithmetic{ctx: dc}
+	value, err := calculate[*big.Rat](ctx, c, expression, arith)
+	if err != nil {
+		return ContextResult{Flags: arith.flags}, err
+	}
+	return ContextResult{Value: value, Flags: arith.flags}, nil
+}
+
+// round rounds a value to the context's precision and exponent limits,
+// returning the signals raised. A nil value means the result overflowed.
+func (c Context) round(value *big.Rat) (*big.Rat, Signal) {
+	if value.Sign() == 0 {
+		return value, 0
+	}
+
+	var signals Signal
+	exponent := decimalExponent(value)
+
+	// Subnormal values keep fewer digits, down to the place of 10^(Emin-Precision+1)
+	subnormal := c.Emin != 0 && exponent < c.Emin
+	if subnormal {
+		signals |= SignalSubnormal
+	}
+
+	if c.Precision > 0 {
+		leastDigit := exponent - c.Precision + 1
+		if subnormal {
+			leastDigit = max(leastDigit, c.Emin-c.Precision+1)
+		}
+
+		unscaled, exact := roundRat(new(big.Rat).Mul(value, pow10(-leastDigit)), c.Rounding)
+		if !exact {
+			signals |= SignalInexact | SignalRounded
+			if subnormal {
+				signals |= SignalUnderflow
+			}
+			value = new(big.Rat).Mul(new(big.Rat).SetInt(unscaled), pow10(leastDigit))
+		}
+	}
+
+	// Rounding may carry into the next power of ten, so check the limit afterwards
+	if c.Emax != 0 && value.Sign() != 0 && decimalExponent(value) > c.Emax {
+		return nil, signals | SignalOverflow | SignalInexact | SignalRounded
+	}
+	return value, signals
+}
+
+// trapped returns the error for the first trapped signal in signals, if any
+func (c Context) trapped(signals Signal, position int) error {
+	trapped := signals & c.Traps
+	if trapped == 0 {
+		return nil
+	}
+	if trapped.Has(SignalInvalidOperation) {
+		return SignalError{Signal: SignalInvalidOperation, Position: position}
+	}
+	if trapped.Has(SignalDivisionByZero) {
+		return DivisionByZeroError{Position: position}
+	}
+	for i := len(signalNames) - 1; i >= 0; i-- {
+		if s := Signal(1 << i); trapped.Has(s) {
+			return SignalError{Signal: s, Position: position}
+		}
+	}
+	return nil
+}
+
+// contextArithmetic evaluates with rationals rounded by a Context, recording
+// the signals raised
+type contextArithmetic struct {
+	ctx   Context
+	flags Signal
+}
+
+func (a *contextArithmetic) number(token Token, number *Number) (*big.Rat, error) {
+	return new(big.Rat).Set(number.Value), nil
+}
+
+func (a *contextArithmetic) apply(left, right *big.Rat, token Token) (*big.Rat, error) {
+	if left == nil || right == nil {
+		return nil, nil
+	}
+
+	var value *big.Rat
+	var signals Signal
+	if token.Value == "/" && right.Sign() == 0 {
+		if left.Sign() == 0 {
+			signals = SignalInvalidOperation
+		} else {
+			signals = SignalDivisionByZero
+		}
+	} else {
+		result, err := performOperation(left, right, rune(token.Value[0]), token.Position)
+		if err != nil {
+			return nil, err
+		}
+		value, signals = a.ctx.round(result)
+	}
+
+	a.flags |= signals
+	if err := a.ctx.trapped(signals, token.Position); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (a *contextArithmetic) bits(value *big.Rat) int {
+	return ratBits(value)
+}
@@ -1,12 +1,92 @@
 package calculator
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCode is a stable, machine-readable identifier for a kind of error
+type ErrorCode string
+
+const (
+	CodeParse            ErrorCode = "PARSE_ERROR"
+	CodeDivisionByZero   ErrorCode = "DIVISION_BY_ZERO"
+	CodeInvalidCharacter ErrorCode = "INVALID_CHARACTER"
+	CodeEmptyExpression  ErrorCode = "EMPTY_EXPRESSION"
+	CodeInvalidBase      ErrorCode = "INVALID_BASE"
+	CodeInvalidPattern   ErrorCode = "INVALID_PATTERN"
+	CodeRoundingRequired ErrorCode = "ROUNDING_REQUIRED"
+	CodeOverflow         ErrorCode = "NUMERIC_OVERFLOW"
+	CodeInvalidPrecision ErrorCode = "INVALID_PRECISION"
+	CodeInvalidOption    ErrorCode = "INVALID_OPTION"
+	CodeUnboundVariable  ErrorCode = "UNBOUND_VARIABLE"
+	CodeCall             ErrorCode = "CALL_ERROR"
+	CodeSignal           ErrorCode = "SIGNAL"
+	CodeInputTooLong     ErrorCode = "INPUT_TOO_LONG"
+	CodeTooManyTokens    ErrorCode = "TOO_MANY_TOKENS"
+	CodeDepthLimit       ErrorCode = "DEPTH_LIMIT_EXCEEDED"
+	CodeNumberTooLarge   ErrorCode = "NUMBER_TOO_LARGE"
+	CodeStepLimit        ErrorCode = "STEP_LIMIT_EXCEEDED"
+	CodeCanceled         ErrorCode = "CANCELED"
+)
+
+// Sentinel errors for use with errors.Is. Each error type matches the
+// sentinel of its own kind and that of its category: ErrSyntax for problems in
+// the expression text, ErrArithmetic for failures during evaluation,
+// ErrConfiguration for invalid formatting, precision or Calculator settings
+// and missing bindings, and ErrLimit for expressions that exceed the
+// configured Limits.
+// CanceledError belongs to no category; it matches ErrCanceled and the context
+// error it wraps.
+var (
+	ErrSyntax        = errors.New("syntax error")
+	ErrArithmetic    = errors.New("arithmetic error")
+	ErrConfiguration = errors.New("configuration error")
+	ErrLimit         = errors.New("limit exceeded")
+
+	ErrParse            = errors.New("parse error")
+	ErrDivisionByZero   = errors.New("division by zero")
+	ErrInvalidCharacter = errors.New("invalid character")
+	ErrEmptyExpression  = errors.New("empty expression")
+	ErrInvalidBase      = errors.New("invalid base")
+	ErrInvalidPattern   = errors.New("invalid format pattern")
+	ErrRoundingRequired = errors.New("rounding required")
+	ErrOverflow         = errors.New("numeric overflow")
+	ErrInvalidPrecision = errors.New("invalid precision")
+	ErrInvalidOption    = errors.New("invalid option")
+	ErrUnboundVariable  = errors.New("unbound variable")
+	ErrCall             = errors.New("call failed")
+	ErrSignal           = errors.New("trapped signal")
+	ErrInputTooLong     = errors.New("input too long")
+	ErrTooManyTokens    = errors.New("too many tokens")
+	ErrDepthLimit       = errors.New("depth limit exceeded")
+	ErrNumberTooLarge   = errors.New("number too large")
+	ErrStepLimit        = errors.New("step limit exceeded")
+	ErrCanceled         = errors.New("calculation canceled")
+)
+
+// CodeOf returns the code of the first error in err's tree that has one, or
+// "" if there is none
+func CodeOf(err error) ErrorCode {
+	var coded interface{ Code() ErrorCode }
+	if errors.As(err, &coded) {
+		return coded.Code()
+	}
+	return ""
+}
 
 // ParseError represents expression parsing failures
 type ParseError struct {
 	Message  string
 	Position int
+	End      int
+	Location Location
 	Context  string
+	// Err is the underlying failure, such as the error from ParseNumber for
+	// a malformed literal
+	Err error
+	// Suggestion is a hint for fixing the expression, or ""
+	Suggestion string
 }
 
 func (e ParseError) Error() string {
@@ -16,28 +96,721 @@ func (e ParseError) Error() string {
 	return fmt.Sprintf("Parse error: %s", e.Message)
 }
 
+// Code returns CodeParse
+func (e ParseError) Code() ErrorCode {
+	return CodeParse
+}
+
+// Is reports whether target is ErrParse or ErrSyntax
+func (e ParseError) Is(target error) bool {
+	return target == ErrParse || target == ErrSyntax
+}
+
+// Unwrap returns the underlying failure, if any
+func (e ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Span returns the rune offsets of the start and end of the offending text
+func (e ParseError) Span() (int, int) {
+	return e.Position, spanEnd(e.Position, e.End)
+}
+
+// Snippet returns the expression with the offending text underlined
+func (e ParseError) Snippet() string {
+	return e.Context
+}
+
+// Where returns the line and column of the start of the offending text
+func (e ParseError) Where() Location {
+	return e.Location
+}
+
+// withSource returns a copy with the location, span end and snippet filled
+// in from the expression
+func (e ParseError) withSource(expression string) error {
+	e.Location, e.End, e.Context = annotate(expression, e.Position, e.End)
+	return e
+}
+
 // DivisionByZeroError represents division by zero attempts
 type DivisionByZeroError struct {
 	Position int
+	End      int
+	Location Location
+	Context  string
 }
 
 func (e DivisionByZeroError) Error() string {
 	return "Division by zero"
 }
 
+// Code returns CodeDivisionByZero
+func (e DivisionByZeroError) Code() ErrorCode {
+	return CodeDivisionByZero
+}
+
+// Is reports whether target is ErrDivisionByZero or ErrArithmetic
+func (e DivisionByZeroError) Is(target error) bool {
+	return target == ErrDivisionByZero || target == ErrArithmetic
+}
+
+// Span returns the rune offsets of the start and end of the offending text
+func (e DivisionByZeroError) Span() (int, int) {
+	return e.Position, spanEnd(e.Position, e.End)
+}
+
+// Snippet returns the expression with the offending text underlined
+func (e DivisionByZeroError) Snippet() string {
+	return e.Context
+}
+
+// Where returns the line and column of the start of the offending text
+func (e DivisionByZeroError) Where() Location {
+	return e.Location
+}
+
+// withSource returns a copy with the location, span end and snippet filled
+// in from the expression
+func (e DivisionByZeroError) withSource(expression string) error {
+	e.Location, e.End, e.Context = annotate(expression, e.Position, e.End)
+	return e
+}
+
 // InvalidCharacterError represents forbidden characters in input
 type InvalidCharacterError struct {
 	Character rune
 	Position  int
+	End       int
+	Location  Location
+	Context   string
+	// Suggestion is a hint for fixing the expression, such as "use 'x' for
+	// multiplication", or ""
+	Suggestion string
 }
 
 func (e InvalidCharacterError) Error() string {
 	return fmt.Sprintf("Invalid character '%c' at position %d", e.Character, e.Position)
 }
 
+// Code returns CodeInvalidCharacter
+func (e InvalidCharacterError) Code() ErrorCode {
+	return CodeInvalidCharacter
+}
+
+// Is reports whether target is ErrInvalidCharacter or ErrSyntax
+func (e InvalidCharacterError) Is(target error) bool {
+	return target == ErrInvalidCharacter || target == ErrSyntax
+}
+
+// Span returns the rune offsets of the start and end of the offending text
+func (e InvalidCharacterError) Span() (int, int) {
+	return e.Position, spanEnd(e.Position, e.End)
+}
+
+// Snippet returns the expression with the offending text underlined
+func (e InvalidCharacterError) Snippet() string {
+	return e.Context
+}
+
+// Where returns the line and column of the start of the offending text
+func (e InvalidCharacterError) Where() Location {
+	return e.Location
+}
+
+// withSource returns a copy with the location, span end and snippet filled
+// in from the expression
+func (e InvalidCharacterError) withSource(expression string) error {
+	e.Location, e.End, e.Context = annotate(expression, e.Position, e.End)
+	return e
+}
+
 // EmptyExpressionError represents empty input
 type EmptyExpressionError struct{}
 
 func (e EmptyExpressionError) Error() string {
 	return "Empty expression provided"
 }
+
+// Code returns CodeEmptyExpression
+func (e EmptyExpressionError) Code() ErrorCode {
+	return CodeEmptyExpression
+}
+
+// Is reports whether target is ErrEmptyExpression or ErrSyntax
+func (e EmptyExpressionError) Is(target error) bool {
+	return target == ErrEmptyExpression || target == ErrSyntax
+}
+
+// ErrorList represents every problem found in an expression by ValidateAll.
+// errors.Is and errors.As examine each element.
+type ErrorList []error
+
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "No errors"
+	case 1:
+		return l[0].Error()
+	case 2:
+		return l[0].Error() + " (and 1 more error)"
+	}
+	return fmt.Sprintf("%s (and %d more errors)", l[0].Error(), len(l)-1)
+}
+
+// Unwrap returns the errors in the list
+func (l ErrorList) Unwrap() []error {
+	return l
+}
+
+// InvalidBaseError represents an output radix outside the supported range
+type InvalidBaseError struct {
+	Base int
+}
+
+func (e InvalidBaseError) Error() string {
+	return fmt.Sprintf("Invalid base %d: must be between 2 and 36", e.Base)
+}
+
+// Code returns CodeInvalidBase
+func (e InvalidBaseError) Code() ErrorCode {
+	return CodeInvalidBase
+}
+
+// Is reports whether target is ErrInvalidBase or ErrConfiguration
+func (e InvalidBaseError) Is(target error) bool {
+	return target == ErrInvalidBase || target == ErrConfiguration
+}
+
+// InvalidPatternError represents a malformed number format pattern
+type InvalidPatternError struct {
+	Pattern string
+	Message string
+}
+
+func (e InvalidPatternError) Error() string {
+	return fmt.Sprintf("Invalid format pattern %q: %s", e.Pattern, e.Message)
+}
+
+// Code returns CodeInvalidPattern
+func (e InvalidPatternError) Code() ErrorCode {
+	return CodeInvalidPattern
+}
+
+// Is reports whether target is ErrInvalidPattern or ErrConfiguration
+func (e InvalidPatternError) Is(target error) bool {
+	return target == ErrInvalidPattern || target == ErrConfiguration
+}
+
+// RoundingRequiredError represents an operation whose exact result cannot be
+// kept when rounding has been declared unnecessary
+type RoundingRequiredError struct {
+	Position int
+	End      int
+	Location Location
+	Context  string
+}
+
+func (e RoundingRequiredError) Error() string {
+	if e.Position >= 0 {
+		return fmt.Sprintf("Rounding required at position %d: result is not exact", e.Position)
+	}
+	return "Rounding required: result is not exact"
+}
+
+// Code returns CodeRoundingRequired
+func (e RoundingRequiredError) Code() ErrorCode {
+	return CodeRoundingRequired
+}
+
+// Is reports whether target is ErrRoundingRequired or ErrArithmetic
+func (e RoundingRequiredError) Is(target error) bool {
+	return target == ErrRoundingRequired || target == ErrArithmetic
+}
+
+// Span returns the rune offsets of the start and end of the offending text
+func (e RoundingRequiredError) Span() (int, int) {
+	return e.Position, spanEnd(e.Position, e.End)
+}
+
+// Snippet returns the expression with the offending text underlined
+func (e RoundingRequiredError) Snippet() string {
+	return e.Context
+}
+
+// Where returns the line and column of the start of the offending text
+func (e RoundingRequiredError) Where() Location {
+	return e.Location
+}
+
+// withSource returns a copy with the location, span end and snippet filled
+// in from the expression
+func (e RoundingRequiredError) withSource(expression string) error {
+	e.Location, e.End, e.Context = annotate(expression, e.Position, e.End)
+	return e
+}
+
+// OverflowError represents a value too large for a fixed-precision decimal type
+type OverflowError struct {
+	Position  int
+	Precision int
+	Scale     int
+	End       int
+	Location  Location
+	Context   string
+}
+
+func (e OverflowError) Error() string {
+	if e.Position >= 0 {
+		return fmt.Sprintf("Numeric overflow at position %d: value does not fit NUMERIC(%d,%d)", e.Position, e.Precision, e.Scale)
+	}
+	return fmt.Sprintf("Numeric overflow: value does not fit NUMERIC(%d,%d)", e.Precision, e.Scale)
+}
+
+// Code returns CodeOverflow
+func (e OverflowError) Code() ErrorCode {
+	return CodeOverflow
+}
+
+// Is reports whether target is ErrOverflow or ErrArithmetic
+func (e OverflowError) Is(target error) bool {
+	return target == ErrOverflow || target == ErrArithmetic
+}
+
+// Span returns the rune offsets of the start and end of the offending text
+func (e OverflowError) Span() (int, int) {
+	return e.Position, spanEnd(e.Position, e.End)
+}
+
+// Snippet returns the expression with the offending text underlined
+func (e OverflowError) Snippet() string {
+	return e.Context
+}
+
+// Where returns the line and column of the start of the offending text
+func (e OverflowError) Where() Location {
+	return e.Location
+}
+
+// withSource returns a copy with the location, span end and snippet filled
+// in from the expression
+func (e OverflowError) withSource(expression string) error {
+	e.Location, e.End, e.Context = annotate(expression, e.Position, e.End)
+	return e
+}
+
+// InvalidPrecisionError represents an unusable precision and scale pair
+type InvalidPrecisionError struct {
+	Precision int
+	Scale     int
+}
+
+func (e InvalidPrecisionError) Error() string {
+	return fmt.Sprintf("Invalid precision %d and scale %d: need precision >= 1 and 0 <= scale <= precision", e.Precision, e.Scale)
+}
+
+// Code returns CodeInvalidPrecision
+func (e InvalidPrecisionError) Code() ErrorCode {
+	return CodeInvalidPrecision
+}
+
+// Is reports whether target is ErrInvalidPrecision or ErrConfiguration
+func (e InvalidPrecisionError) Is(target error) bool {
+	return target == ErrInvalidPrecision || target == ErrConfiguration
+}
+
+// InvalidOptionError represents a Calculator option that cannot be applied
+type InvalidOptionError struct {
+	Option  string
+	Message string
+}
+
+func (e InvalidOptionError) Error() string {
+	return fmt.Sprintf("Invalid option %s: %s", e.Option, e.Message)
+}
+
+// Code returns CodeInvalidOption
+func (e InvalidOptionError) Code() ErrorCode {
+	return CodeInvalidOption
+}
+
+// Is reports whether target is ErrInvalidOption or ErrConfiguration
+func (e InvalidOptionError) Is(target error) bool {
+	return target == ErrInvalidOption || target == ErrConfiguration
+}
+
+// SignalError represents a trapped signal raised while evaluating in a Context
+type SignalError struct {
+	Signal   Signal
+	Position int
+	End      int
+	Location Location
+	Context  string
+}
+
+func (e SignalError) Error() string {
+	return fmt.Sprintf("%s signal at position %d", e.Signal, e.Position)
+}
+
+// Code returns CodeSignal
+func (e SignalError) Code() ErrorCode {
+	return CodeSignal
+}
+
+// Is reports whether target is ErrSignal or ErrArithmetic
+func (e SignalError) Is(target error) bool {
+	return target == ErrSignal || target == ErrArithmetic
+}
+
+// Span returns the rune offsets of the start and end of the offending text
+func (e SignalError) Span() (int, int) {
+	return e.Position, spanEnd(e.Position, e.End)
+}
+
+// Snippet returns the expression with the offending text underlined
+func (e SignalError) Snippet() string {
+	return e.Context
+}
+
+// Where returns the line and column of the start of the offending text
+func (e SignalError) Where() Location {
+	return e.Location
+}
+
+// withSource returns a copy with the location, span end and snippet filled
+// in from the expression
+func (e SignalError) withSource(expression string) error {
+	e.Location, e.End, e.Context = annotate(expression, e.Position, e.End)
+	return e
+}
+
+// InputTooLongError represents an expression longer than Limits.MaxLength
+type InputTooLongError struct {
+	Length int
+	Limit  int
+}
+
+func (e InputTooLongError) Error() string {
+	return fmt.Sprintf("Expression is %d characters long, more than the limit of %d", e.Length, e.Limit)
+}
+
+// Code returns CodeInputTooLong
+func (e InputTooLongError) Code() ErrorCode {
+	return CodeInputTooLong
+}
+
+// Is reports whether target is ErrInputTooLong or ErrLimit
+func (e InputTooLongError) Is(target error) bool {
+	return target == ErrInputTooLong || target == ErrLimit
+}
+
+// TooManyTokensError represents an expression with more than Limits.MaxTokens
+// tokens. Position is that of the first token over the limit.
+type TooManyTokensError struct {
+	Limit    int
+	Position int
+	End      int
+	Location Location
+	Context  string
+}
+
+func (e TooManyTokensError) Error() string {
+	return fmt.Sprintf("Too many tokens at position %d: the limit is %d", e.Position, e.Limit)
+}
+
+// Code returns CodeTooManyTokens
+func (e TooManyTokensError) Code() ErrorCode {
+	return CodeTooManyTokens
+}
+
+// Is reports whether target is ErrTooManyTokens or ErrLimit
+func (e TooManyTokensError) Is(target error) bool {
+	return target == ErrTooManyTokens || target == ErrLimit
+}
+
+// Span returns the rune offsets of the start and end of the offending text
+func (e TooManyTokensError) Span() (int, int) {
+	return e.Position, spanEnd(e.Position, e.End)
+}
+
+// Snippet returns the expression with the offending text underlined
+func (e TooManyTokensError) Snippet() string {
+	return e.Context
+}
+
+// Where returns the line and column of the start of the offending text
+func (e TooManyTokensError) Where() Location {
+	return e.Location
+}
+
+// withSource returns a copy with the location, span end and snippet filled
+// in from the expression
+func (e TooManyTokensError) withSource(expression string) error {
+	e.Location, e.End, e.Context = annotate(expression, e.Position, e.End)
+	return e
+}
+
+// DepthLimitError represents evaluation needing more than Limits.MaxDepth
+// values on the stack at once
+type DepthLimitError struct {
+	Limit    int
+	Position int
+	End      int
+	Location Location
+	Context  string
+}
+
+func (e DepthLimitError) Error() string {
+	return fmt.Sprintf("Evaluation depth exceeds the limit of %d at position %d", e.Limit, e.Position)
+}
+
+// Code returns CodeDepthLimit
+func (e DepthLimitError) Code() ErrorCode {
+	return CodeDepthLimit
+}
+
+// Is reports whether target is ErrDepthLimit or ErrLimit
+func (e DepthLimitError) Is(target error) bool {
+	return target == ErrDepthLimit || target == ErrLimit
+}
+
+// Span returns the rune offsets of the start and end of the offending text
+func (e DepthLimitError) Span() (int, int) {
+	return e.Position, spanEnd(e.Position, e.End)
+}
+
+// Snippet returns the expression with the offending text underlined
+func (e DepthLimitError) Snippet() string {
+	return e.Context
+}
+
+// Where returns the line and column of the start of the offending text
+func (e DepthLimitError) Where() Location {
+	return e.Location
+}
+
+// withSource returns a copy with the location, span end and snippet filled
+// in from the expression
+func (e DepthLimitError) withSource(expression string) error {
+	e.Location, e.End, e.Context = annotate(expression, e.Position, e.End)
+	return e
+}
+
+// NumberTooLargeError represents a literal or intermediate result whose
+// numerator or denominator needs more than Limits.MaxBits bits
+type NumberTooLargeError struct {
+	Bits     int
+	Limit    int
+	Position int
+	End      int
+	Location Location
+	Context  string
+}
+
+func (e NumberTooLargeError) Error() string {
+	return fmt.Sprintf("Number too large at position %d: %d bits exceeds the limit of %d", e.Position, e.Bits, e.Limit)
+}
+
+// Code returns CodeNumberTooLarge
+func (e NumberTooLargeError) Code() ErrorCode {
+	return CodeNumberTooLarge
+}
+
+// Is reports whether target is ErrNumberTooLarge or ErrLimit
+func (e NumberTooLargeError) Is(target error) bool {
+	return target == ErrNumberTooLarge || target == ErrLimit
+}
+
+// Span returns the rune offsets of the start and end of the offending text
+func (e NumberTooLargeError) Span() (int, int) {
+	return e.Position, spanEnd(e.Position, e.End)
+}
+
+// Snippet returns the expression with the offending text underlined
+func (e NumberTooLargeError) Snippet() string {
+	return e.Context
+}
+
+// Where returns the line and column of the start of the offending text
+func (e NumberTooLargeError) Where() Location {
+	return e.Location
+}
+
+// withSource returns a copy with the location, span end and snippet filled
+// in from the expression
+func (e NumberTooLargeError) withSource(expression string) error {
+	e.Location, e.End, e.Context = annotate(expression, e.Position, e.End)
+	return e
+}
+
+// StepLimitError represents evaluation taking more than Limits.MaxSteps
+// operations
+type StepLimitError struct {
+	Limit    int
+	Position int
+	End      int
+	Location Location
+	Context  string
+}
+
+func (e StepLimitError) Error() string {
+	return fmt.Sprintf("Evaluation exceeds the limit of %d steps at position %d", e.Limit, e.Position)
+}
+
+// Code returns CodeStepLimit
+func (e StepLimitError) Code() ErrorCode {
+	return CodeStepLimit
+}
+
+// Is reports whether target is ErrStepLimit or ErrLimit
+func (e StepLimitError) Is(target error) bool {
+	return target == ErrStepLimit || target == ErrLimit
+}
+
+// Span returns the rune offsets of the start and end of the offending text
+func (e StepLimitError) Span() (int, int) {
+	return e.Position, spanEnd(e.Position, e.End)
+}
+
+// Snippet returns the expression with the offending text underlined
+func (e StepLimitError) Snippet() string {
+	return e.Context
+}
+
+// Where returns the line and column of the start of the offending text
+func (e StepLimitError) Where() Location {
+	return e.Location
+}
+
+// withSource returns a copy with the location, span end and snippet filled
+// in from the expression
+func (e StepLimitError) withSource(expression string) error {
+	e.Location, e.End, e.Context = annotate(expression, e.Position, e.End)
+	return e
+}
+
+// UnboundVariableError represents a variable in a Program that was given no
+// value when evaluated
+type UnboundVariableError struct {
+	Name     string
+	Position int
+	End      int
+	Location Location
+	Context  string
+}
+
+func (e UnboundVariableError) Error() string {
+	return fmt.Sprintf("No value bound to variable %s at position %d", e.Name, e.Position)
+}
+
+// Code returns CodeUnboundVariable
+func (e UnboundVariableError) Code() ErrorCode {
+	return CodeUnboundVariable
+}
+
+// Is reports whether target is ErrUnboundVariable or ErrConfiguration
+func (e UnboundVariableError) Is(target error) bool {
+	return target == ErrUnboundVariable || target == ErrConfiguration
+}
+
+// Span returns the rune offsets of the start and end of the offending text
+func (e UnboundVariableError) Span() (int, int) {
+	return e.Position, spanEnd(e.Position, e.End)
+}
+
+// Snippet returns the expression with the offending text underlined
+func (e UnboundVariableError) Snippet() string {
+	return e.Context
+}
+
+// Where returns the line and column of the start of the offending text
+func (e UnboundVariableError) Where() Location {
+	return e.Location
+}
+
+// withSource returns a copy with the location, span end and snippet filled
+// in from the expression
+func (e UnboundVariableError) withSource(expression string) error {
+	e.Location, e.End, e.Context = annotate(expression, e.Position, e.End)
+	return e
+}
+
+// CallError represents a failure returned by a custom operator or by a
+// function registered with WithFunction, at the position of the operator or
+// the function's name. Err is the returned error, so errors.Is and errors.As
+// see through to it.
+type CallError struct {
+	Name     string
+	Position int
+	End      int
+	Location Location
+	Context  string
+	Err      error
+}
+
+func (e CallError) Error() string {
+	return fmt.Sprintf("%s failed at position %d: %v", e.Name, e.Position, e.Err)
+}
+
+// Code returns CodeCall
+func (e CallError) Code() ErrorCode {
+	return CodeCall
+}
+
+// Is reports whether target is ErrCall or ErrArithmetic
+func (e CallError) Is(target error) bool {
+	return target == ErrCall || target == ErrArithmetic
+}
+
+// Unwrap returns the error from the implementation
+func (e CallError) Unwrap() error {
+	return e.Err
+}
+
+// Span returns the rune offsets of the start and end of the offending text
+func (e CallError) Span() (int, int) {
+	return e.Position, spanEnd(e.Position, e.End)
+}
+
+// Snippet returns the expression with the offending text underlined
+func (e CallError) Snippet() string {
+	return e.Context
+}
+
+// Where returns the line and column of the start of the offending text
+func (e CallError) Where() Location {
+	return e.Location
+}
+
+// withSource returns a copy with the location, span end and snippet filled
+// in from the expression
+func (e CallError) withSource(expression string) error {
+	e.Location, e.End, e.Context = annotate(expression, e.Position, e.End)
+	return e
+}
+
+// CanceledError represents an evaluation stopped because its context was
+// canceled or its deadline passed. Err is the context's error, so errors.Is
+// matches context.Canceled or context.DeadlineExceeded.
+type CanceledError struct {
+	Err error
+}
+
+func (e CanceledError) Error() string {
+	return fmt.Sprintf("Calculation canceled: %v", e.Err)
+}
+
+// Code returns CodeCanceled
+func (e CanceledError) Code() ErrorCode {
+	return CodeCanceled
+}
+
+// Is reports whether target is ErrCanceled
+func (e CanceledError) Is(target error) bool {
+	return target == ErrCanceled
+}
+
+// Unwrap returns the context's error
+func (e CanceledError) Unwrap() error {
+	return e.Err
+}
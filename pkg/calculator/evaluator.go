@@ -1,59 +1,194 @@
 package calculator
 
 import (
+	"context"
 	"math/big"
 )
 
+// arithmetic supplies the value domain a postfix expression is evaluated in,
+// letting the exact, significant-figure and decimal modes share one stack walk
+type arithmetic[T any] interface {
+	// number converts a parsed number literal into a value
+	number(token Token, number *Number) (T, error)
+	// apply combines two operands with the operator in token
+	apply(left, right T, token Token) (T, error)
+	// bits returns the size of a value for Limits.MaxBits
+	bits(value T) int
+}
+
+// customArithmetic is implemented by the arithmetics that support prefix
+// operators and function calls, which are only registered for exact
+// evaluation
+type customArithmetic[T any] interface {
+	// unary applies the prefix operator in token to operand
+	unary(operand T, token Token) (T, error)
+	// call calls the function in token with its arguments
+	call(token Token, args []slot[T]) (T, error)
+}
+
+// slot is an entry on the evaluation stack: a value, or the text of a quoted
+// function argument
+type slot[T any] struct {
+	value  T
+	text   string
+	isText bool
+}
+
 // EvaluatePostfix evaluates postfix expression to get final result
 func EvaluatePostfix(tokens []Token) (*big.Rat, error) {
-	stack := []*big.Rat{}
+	return evaluate[*big.Rat](context.Background(), tokens, ratArithmetic{}, Limits{}, nil)
+}
+
+// evaluate walks postfix tokens, keeping operands on a stack of values and
+// enforcing the depth, size and step limits. Variables take their values from
+// bindings. ctx is checked before each operation, since a single big.Rat
+// operation cannot be interrupted.
+func evaluate[T any](ctx context.Context, tokens []Token, arith arithmetic[T], limits Limits, bindings Bindings) (T, error) {
+	var zero T
+	stack := []slot[T]{}
+	steps := 0
 
 	for _, token := range tokens {
 		switch token.Type {
-		case NumberToken:
-			// Parse the number based on its format
-			var num *big.Rat
-			var err error
-
-			if len(token.Value) >= 2 && token.Value[:2] == "0x" {
-				num, err = ParseHexadecimal(token.Value)
-			} else if len(token.Value) >= 3 && token.Value[:3] == "-0x" {
-				num, err = ParseHexadecimal(token.Value)
-			} else {
-				num, err = ParseDecimal(token.Value)
+		case NumberToken, VariableToken:
+			number, err := tokenNumber(token, bindings)
+			if err != nil {
+				return zero, err
 			}
 
+			value, err := arith.number(token, number)
 			if err != nil {
-				return nil, ParseError{Message: "Invalid number format: " + token.Value, Position: token.Position}
+				return zero, err
+			}
+			if err := limits.checkBits(arith.bits(value), token); err != nil {
+				return zero, err
 			}
 
-			stack = append(stack, num)
+			stack = append(stack, slot[T]{value: value})
+			if limits.MaxDepth > 0 && len(stack) > limits.MaxDepth {
+				return zero, DepthLimitError{Limit: limits.MaxDepth, Position: token.Position, End: tokenEnd(token)}
+			}
+
+		case TextToken:
+			stack = append(stack, slot[T]{text: token.Value[1 : len(token.Value)-1], isText: true})
 
-		case OperatorToken:
-			if len(stack) < 2 {
-				return nil, ParseError{Message: "Insufficient operands for operator", Position: token.Position}
+		case OperatorToken, FunctionToken:
+			arity := 2
+			switch {
+			case token.Type == FunctionToken:
+				arity = token.Args
+			case token.Operator != nil && token.Operator.unary():
+				arity = 1
+			}
+			if len(stack) < arity {
+				return zero, ParseError{Message: "Insufficient operands for operator", Position: token.Position}
 			}
 
-			// Pop two operands
-			right := stack[len(stack)-1]
-			left := stack[len(stack)-2]
-			stack = stack[:len(stack)-2]
+			// Pop the operands
+			operands := stack[len(stack)-arity:]
+			stack = stack[:len(stack)-arity]
+
+			if err := ctx.Err(); err != nil {
+				return zero, CanceledError{Err: err}
+			}
+
+			steps++
+			if limits.MaxSteps > 0 && steps > limits.MaxSteps {
+				return zero, StepLimitError{Limit: limits.MaxSteps, Position: token.Position}
+			}
 
 			// Perform operation
-			result, err := performOperation(left, right, rune(token.Value[0]), token.Position)
+			var result T
+			var err error
+			custom, ok := any(arith).(customArithmetic[T])
+			switch {
+			case (token.Type == FunctionToken || arity == 1) && !ok:
+				return zero, ParseError{Message: token.Value + " is not supported in this mode", Position: token.Position, End: tokenEnd(token)}
+			case token.Type == FunctionToken:
+				result, err = custom.call(token, operands)
+			case arity == 1:
+				result, err = custom.unary(operands[0].value, token)
+			default:
+				result, err = arith.apply(operands[0].value, operands[1].value, token)
+			}
 			if err != nil {
-				return nil, err
+				return zero, err
+			}
+			if err := limits.checkBits(arith.bits(result), token); err != nil {
+				return zero, err
 			}
 
-			stack = append(stack, result)
+			stack = append(stack, slot[T]{value: result})
 		}
 	}
 
-	if len(stack) != 1 {
-		return nil, ParseError{Message: "Invalid expression structure", Position: 0}
+	if len(stack) != 1 || stack[0].isText {
+		return zero, ParseError{Message: "Invalid expression structure", Position: 0}
 	}
 
-	return stack[0], nil
+	return stack[0].value, nil
+}
+
+// tokenNumber returns the number parsed by the tokenizer or bound to a
+// variable, falling back to the raw text for tokens built by hand
+func tokenNumber(token Token, bindings Bindings) (*Number, error) {
+	if token.Type == VariableToken {
+		value, ok := bindings[token.Value]
+		if !ok || value == nil {
+			return nil, UnboundVariableError{Name: token.Value, Position: token.Position, End: tokenEnd(token)}
+		}
+		return &Number{Value: value, Original: token.Value, Type: Decimal}, nil
+	}
+	if token.Number != nil {
+		return token.Number, nil
+	}
+	number, err := ParseNumber(token.Value)
+	if err != nil {
+		return nil, ParseError{Message: "Invalid number format: " + token.Value, Position: token.Position, End: tokenEnd(token), Location: token.Location, Err: err}
+	}
+	return number, nil
+}
+
+// ratArithmetic evaluates with exact rationals
+type ratArithmetic struct{}
+
+func (ratArithmetic) number(token Token, number *Number) (*big.Rat, error) {
+	return new(big.Rat).Set(number.Value), nil
+}
+
+func (ratArithmetic) apply(left, right *big.Rat, token Token) (*big.Rat, error) {
+	if op := token.Operator; op != nil && op.Apply != nil {
+		return applyOperator(op, token, left, right)
+	}
+	return performOperation(left, right, rune(token.Value[0]), token.Position)
+}
+
+func (ratArithmetic) unary(operand *big.Rat, token Token) (*big.Rat, error) {
+	return applyOperator(token.Operator, token, operand)
+}
+
+func (ratArithmetic) call(token Token, args []slot[*big.Rat]) (*big.Rat, error) {
+	arguments := make([]Argument, len(args))
+	for i, arg := range args {
+		if arg.isText {
+			arguments[i] = Argument{Text: arg.text}
+		} else {
+			arguments[i] = Argument{Value: arg.value}
+		}
+	}
+	return callFunction(token, arguments)
+}
+
+func (ratArithmetic) bits(value *big.Rat) int {
+	return ratBits(value)
+}
+
+// ratBits returns the larger bit length of a rational's numerator and denominator
+func ratBits(value *big.Rat) int {
+	if value == nil {
+		return 0
+	}
+	return max(value.Num().BitLen(), value.Denom().BitLen())
 }
 
 // performOperation performs a single arithmetic operation
@@ -0,0 +1,200 @@
+package calculator
+
+import (
+	"context"
+	"math/big"
+	"strings"
+)
+
+// Explanation shows how an expression was evaluated: its tokens, the postfix
+// order InfixToPostfix puts them in, the expression with every operation
+// parenthesized to show precedence, and each operation EvaluatePostfix
+// performs with its exact operands and result
+type Explanation struct {
+	Expression string
+	Tokens     []Token
+	Postfix    []Token
+	// Parenthesized is the expression with one pair of parentheses around
+	// each operation, such as "(1 + (2 x 3))"
+	Parenthesized string
+	Steps         []Step
+	// Result is nil when evaluation failed
+	Result *big.Rat
+}
+
+// Step is a single operation performed during evaluation
+type Step struct {
+	// Token is the operator or function applied
+	Token Token
+	// Operands are the values it was applied to, left first; quoted function
+	// arguments are text
+	Operands []Argument
+	Result   *big.Rat
+}
+
+// String writes the step with its exact values, as decimals when they
+// terminate and fractions otherwise, such as "0.2 x 3 = 0.6" or
+// "1 / 3 = 1/3"
+func (s Step) String() string {
+	operands := make([]string, len(s.Operands))
+	for i, operand := range s.Operands {
+		if operand.IsText() {
+			operands[i] = `"` + operand.Text + `"`
+		} else {
+			operands[i] = formatExact(operand.Value)
+		}
+	}
+
+	var sb strings.Builder
+	switch {
+	case s.Token.Type == FunctionToken:
+		sb.WriteString(s.Token.Value + "(" + strings.Join(operands, ", ") + ")")
+	case len(operands) == 1:
+		sb.WriteString(prefixed(s.Token.Value, operands[0]))
+	default:
+		sb.WriteString(strings.Join(operands, " "+s.Token.Value+" "))
+	}
+	sb.WriteString(" = ")
+	sb.WriteString(formatExact(s.Result))
+	return sb.String()
+}
+
+// Explain evaluates an expression like Calculate, recording each step
+func Explain(expression string) (*Explanation, error) {
+	return ExplainWithOptions(expression, Options{})
+}
+
+// ExplainWithOptions evaluates an expression like CalculateWithOptions,
+// recording each step
+func ExplainWithOptions(expression string, opts Options) (*Explanation, error) {
+	return defaultCalculator.withOptions(opts).Explain(expression)
+}
+
+// Explain evaluates an expression like Calculate, recording each step. An
+// expression that cannot be parsed returns only the error; one that fails
+// during evaluation also returns the steps that succeeded before the error.
+func (c *Calculator) Explain(expression string) (*Explanation, error) {
+	var steps []Step
+	record := func(step Step) {
+		steps = append(steps, step)
+	}
+	if observer, ok := c.observer.(OperationObserver); ok {
+		record = func(step Step) {
+			steps = append(steps, step)
+			observer.Operation(step)
+		}
+	}
+
+	expr, result, err := calculateExpression[*big.Rat](context.Background(), c, expression, &tracingArithmetic{record: record})
+	if expr == nil {
+		return nil, err
+	}
+	explanation := &Explanation{
+		Expression:    expression,
+		Tokens:        expr.Tokens,
+		Postfix:       expr.PostfixTokens,
+		Parenthesized: parenthesize(expr.PostfixTokens),
+		Steps:         steps,
+	}
+	if err != nil {
+		return explanation, err
+	}
+	explanation.Result = result
+	return explanation, nil
+}
+
+// tracingArithmetic evaluates with exact rationals, passing each operation
+// to record
+type tracingArithmetic struct {
+	ratArithmetic
+	record func(Step)
+}
+
+func (t *tracingArithmetic) apply(left, right *big.Rat, token Token) (*big.Rat, error) {
+	result, err := t.ratArithmetic.apply(left, right, token)
+	if err == nil {
+		t.step(token, result, Argument{Value: left}, Argument{Value: right})
+	}
+	return result, err
+}
+
+func (t *tracingArithmetic) unary(operand *big.Rat, token Token) (*big.Rat, error) {
+	result, err := t.ratArithmetic.unary(operand, token)
+	if err == nil {
+		t.step(token, result, Argument{Value: operand})
+	}
+	return result, err
+}
+
+func (t *tracingArithmetic) call(token Token, args []slot[*big.Rat]) (*big.Rat, error) {
+	result, err := t.ratArithmetic.call(token, args)
+	if err == nil {
+		operands := make([]Argument, len(args))
+		for i, arg := range args {
+			if arg.isText {
+				operands[i] = Argument{Text: arg.text}
+			} else {
+				operands[i] = Argument{Value: arg.value}
+			}
+		}
+		t.step(token, result, operands...)
+	}
+	return result, err
+}
+
+// step records an operation, copying the values so that later operations
+// cannot change them
+func (t *tracingArithmetic) step(token Token, result *big.Rat, operands ...Argument) {
+	for i, operand := range operands {
+		if !operand.IsText() {
+			operands[i].Value = new(big.Rat).Set(operand.Value)
+		}
+	}
+	t.record(Step{Token: token, Operands: operands, Result: new(big.Rat).Set(result)})
+}
+
+// parenthesize writes postfix tokens back as infix with every operation in
+// parentheses
+func parenthesize(postfix []Token) string {
+	stack := []string{}
+	for _, token := range postfix {
+		switch token.Type {
+		case NumberToken, VariableToken, TextToken:
+			stack = append(stack, token.Value)
+
+		case FunctionToken:
+			if len(stack) < token.Args {
+				return ""
+			}
+			args := stack[len(stack)-token.Args:]
+			stack = append(stack[:len(stack)-token.Args], token.Value+"("+strings.Join(args, ", ")+")")
+
+		case OperatorToken:
+			if token.Operator != nil && token.Operator.unary() {
+				if len(stack) < 1 {
+					return ""
+				}
+				stack[len(stack)-1] = "(" + prefixed(token.Value, stack[len(stack)-1]) + ")"
+				continue
+			}
+			if len(stack) < 2 {
+				return ""
+			}
+			left, right := stack[len(stack)-2], stack[len(stack)-1]
+			stack = append(stack[:len(stack)-2], "("+left+" "+token.Value+" "+right+")")
+		}
+	}
+	if len(stack) != 1 {
+		return ""
+	}
+	return stack[0]
+}
+
+// prefixed writes a prefix operator before its operand, separating operators
+// spelled as words so that "neg 5" does not read as "neg5"
+func prefixed(operator, operand string) string {
+	if last := []rune(operator); isNameRune(last[len(last)-1]) {
+		return operator + " " + operand
+	}
+	return operator + operand
+}
@@ -0,0 +1,107 @@
+package calculator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+)
+
+// FixedDecimal is a fixed-precision decimal type in the manner of SQL
+// NUMERIC(p,s): values have at most Precision digits, Scale of which follow
+// the decimal point. Evaluation rounds every literal and every intermediate
+// result to Scale digits with Rounding, as a database does when each step is
+// stored in a NUMERIC(p,s) column, and fails with an OverflowError when a
+// value needs more than Precision-Scale integer digits. With RoundUnnecessary
+// any step that would lose digits fails with a RoundingRequiredError instead.
+type FixedDecimal struct {
+	Precision int
+	Scale     int
+	Rounding  RoundingMode
+}
+
+// NewFixedDecimal returns a NUMERIC(precision, scale) type rounding half up
+func NewFixedDecimal(precision, scale int) (FixedDecimal, error) {
+	f := FixedDecimal{Precision: precision, Scale: scale, Rounding: RoundHalfUp}
+	if err := f.validate(); err != nil {
+		return FixedDecimal{}, err
+	}
+	return f, nil
+}
+
+// String returns the SQL spelling of the type, e.g. "NUMERIC(18,2)"
+func (f FixedDecimal) String() string {
+	return fmt.Sprintf("NUMERIC(%d,%d)", f.Precision, f.Scale)
+}
+
+// Fit rounds a value to the type's scale and checks that it fits its precision
+func (f FixedDecimal) Fit(value *big.Rat) (ScaledDecimal, error) {
+	if err := f.validate(); err != nil {
+		return ScaledDecimal{}, err
+	}
+	return f.fit(value, -1)
+}
+
+// Calculate evaluates an expression with every step stored as this type
+func (f FixedDecimal) Calculate(expression string) (ScaledDecimal, error) {
+	return defaultCalculator.CalculateFixed(expression, f)
+}
+
+// CalculateFixed evaluates an expression with every step stored as f, like
+// FixedDecimal.Calculate, with the calculator's limits and observer
+func (c *Calculator) CalculateFixed(expression string, f FixedDecimal) (ScaledDecimal, error) {
+	return c.CalculateFixedContext(context.Background(), expression, f)
+}
+
+// CalculateFixedContext evaluates an expression like CalculateFixed,
+// stopping with a CanceledError once ctx is done
+func (c *Calculator) CalculateFixedContext(ctx context.Context, expression string, f FixedDecimal) (ScaledDecimal, error) {
+	if err := f.validate(); err != nil {
+		return ScaledDecimal{}, err
+	}
+
+	return calculate[ScaledDecimal](ctx, c, expression, fixedArithmetic{f})
+}
+
+// validate checks that precision and scale describe a usable type
+func (f FixedDecimal) validate() error {
+	if f.Precision < 1 || f.Scale < 0 || f.Scale > f.Precision {
+		return InvalidPrecisionError{Precision: f.Precision, Scale: f.Scale}
+	}
+	return nil
+}
+
+// fit rounds a value to the scale and checks its precision, reporting
+// failures at the given position
+func (f FixedDecimal) fit(value *big.Rat, position int) (ScaledDecimal, error) {
+	d, exact := NewScaledDecimal(value, f.Scale, f.Rounding)
+	if !exact && f.Rounding == RoundUnnecessary {
+		return ScaledDecimal{}, RoundingRequiredError{Position: position}
+	}
+
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(f.Precision)), nil)
+	if new(big.Int).Abs(d.Unscaled).Cmp(limit) >= 0 {
+		return ScaledDecimal{}, OverflowError{Position: position, Precision: f.Precision, Scale: f.Scale}
+	}
+	return d, nil
+}
+
+// fixedArithmetic evaluates with values rounded to a FixedDecimal after each step
+type fixedArithmetic struct {
+	fixed FixedDecimal
+}
+
+func (a fixedArithmetic) number(token Token, number *Number) (ScaledDecimal, error) {
+	return a.fixed.fit(number.Value, token.Position)
+}
+
+func (a fixedArithmetic) apply(left, right ScaledDecimal, token Token) (ScaledDecimal, error) {
+	value, err := performOperation(left.Rat(), right.Rat(), rune(token.Value[0]), token.Position)
+	if err != nil {
+		return ScaledDecimal{}, err
+	}
+	return a.fixed.fit(value, token.Position)
+}
+
+func (fixedArithmetic) bits(value ScaledDecimal) int {
+	return value.Unscaled.BitLen()
+}
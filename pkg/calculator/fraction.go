@@ -0,0 +1,118 @@
+package calculator
+
+import (
+	"math/big"
+	"strings"
+)
+
+// vulgarFractions maps reduced fractions to their single Unicode glyph
+var vulgarFractions = map[[2]int64]string{
+	{1, 2}: "½", {1, 3}: "⅓", {2, 3}: "⅔", {1, 4}: "¼", {3, 4}: "¾",
+	{1, 5}: "⅕", {2, 5}: "⅖", {3, 5}: "⅗", {4, 5}: "⅘", {1, 6}: "⅙",
+	{5, 6}: "⅚", {1, 7}: "⅐", {1, 8}: "⅛", {3, 8}: "⅜", {5, 8}: "⅝",
+	{7, 8}: "⅞", {1, 9}: "⅑", {1, 10}: "⅒",
+}
+
+// superscriptDigits and subscriptDigits hold the Unicode forms of 0-9
+const (
+	superscriptDigits = "⁰¹²³⁴⁵⁶⁷⁸⁹"
+	subscriptDigits   = "₀₁₂₃₄₅₆₇₈₉"
+)
+
+// FormatMixed formats a rational number as a mixed number, e.g. 7/3 as "2 1/3"
+func FormatMixed(result *big.Rat) string {
+	whole, frac := splitMixed(result)
+
+	var sb strings.Builder
+	if result.Sign() < 0 {
+		sb.WriteByte('-')
+	}
+	if whole.Sign() != 0 || frac.Sign() == 0 {
+		sb.WriteString(whole.String())
+	}
+	if frac.Sign() != 0 {
+		if whole.Sign() != 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(frac.Num().String() + "/" + frac.Denom().String())
+	}
+	return sb.String()
+}
+
+// FormatUnicodeFraction formats a rational number as a mixed number using
+// Unicode fraction glyphs, e.g. 7/3 as "2⅓". Fractions without a dedicated
+// glyph are written with superscript and subscript digits, e.g. "⁷⁄₁₂".
+func FormatUnicodeFraction(result *big.Rat) string {
+	whole, frac := splitMixed(result)
+
+	var sb strings.Builder
+	if result.Sign() < 0 {
+		sb.WriteByte('-')
+	}
+	if whole.Sign() != 0 || frac.Sign() == 0 {
+		sb.WriteString(whole.String())
+	}
+	if frac.Sign() != 0 {
+		sb.WriteString(unicodeFraction(frac))
+	}
+	return sb.String()
+}
+
+// ParseMixedNumber parses a mixed number such as "2 1/3" or "-2 1/3" to exact
+// rational. The sign applies to the whole value.
+func ParseMixedNumber(s string) (*big.Rat, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return nil, ParseError{Message: "Mixed number must be a whole part and a fraction", Position: 0}
+	}
+
+	negative := strings.HasPrefix(fields[0], "-")
+	whole, ok := new(big.Int).SetString(strings.TrimPrefix(fields[0], "-"), 10)
+	if !ok || whole.Sign() < 0 {
+		return nil, ParseError{Message: "Invalid whole part in mixed number", Position: 0}
+	}
+
+	numText, denText, found := strings.Cut(fields[1], "/")
+	num, numOK := new(big.Int).SetString(numText, 10)
+	den, denOK := new(big.Int).SetString(denText, 10)
+	if !found || !numOK || !denOK || num.Sign() < 0 || den.Sign() <= 0 {
+		return nil, ParseError{Message: "Invalid fraction in mixed number", Position: 0}
+	}
+	if num.Cmp(den) >= 0 {
+		return nil, ParseError{Message: "Fraction in mixed number must be proper", Position: 0}
+	}
+
+	rat := new(big.Rat).SetFrac(num, den)
+	rat.Add(rat, new(big.Rat).SetInt(whole))
+	if negative {
+		rat.Neg(rat)
+	}
+	return rat, nil
+}
+
+// splitMixed splits |r| into its whole part and proper fractional part
+func splitMixed(r *big.Rat) (*big.Int, *big.Rat) {
+	num := new(big.Int).Abs(r.Num())
+	whole, rem := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	return whole, new(big.Rat).SetFrac(rem, r.Denom())
+}
+
+// unicodeFraction renders a proper fraction as a glyph or with super/subscripts
+func unicodeFraction(frac *big.Rat) string {
+	if frac.Num().IsInt64() && frac.Denom().IsInt64() {
+		if glyph, ok := vulgarFractions[[2]int64{frac.Num().Int64(), frac.Denom().Int64()}]; ok {
+			return glyph
+		}
+	}
+	return mapDigits(frac.Num().String(), superscriptDigits) + "⁄" + mapDigits(frac.Denom().String(), subscriptDigits)
+}
+
+// mapDigits replaces each ASCII digit with the corresponding rune from table
+func mapDigits(digits, table string) string {
+	forms := []rune(table)
+	var sb strings.Builder
+	for _, ch := range digits {
+		sb.WriteRune(forms[ch-'0'])
+	}
+	return sb.String()
+}
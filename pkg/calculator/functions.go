@@ -0,0 +1,181 @@
+package calculator
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"unicode/utf8"
+)
+
+// Function is a Go function that expressions can call by name, as in
+// "tax(amount)" or "fx("EUR", amount)". Functions are registered on a
+// Calculator with WithFunction.
+type Function struct {
+	Name string
+	// MinArgs and MaxArgs bound the number of arguments. A negative MaxArgs
+	// allows any number from MinArgs up, and one below MinArgs, such as the
+	// zero value, allows exactly MinArgs.
+	MinArgs int
+	MaxArgs int
+	// Apply computes the result from numeric arguments
+	Apply func(args ...*big.Rat) (*big.Rat, error)
+	// ApplyText is set instead of Apply for functions that also take quoted
+	// text arguments, such as the currency code in fx("EUR", amount)
+	ApplyText func(args ...Argument) (*big.Rat, error)
+}
+
+// Argument is an argument passed to Function.ApplyText: quoted text, with a
+// nil Value, or a number
+type Argument struct {
+	Text  string
+	Value *big.Rat
+}
+
+// IsText reports whether the argument is quoted text
+func (a Argument) IsText() bool {
+	return a.Value == nil
+}
+
+// WithFunction adds a function, or replaces the function of the same name.
+// The name must be letters, digits and underscores starting with a letter or
+// underscore, and exactly one of Apply and ApplyText must be set. Calls are
+// checked against MinArgs and MaxArgs when an expression is parsed, and an
+// error from the function is returned as a CallError at the call.
+func WithFunction(fn Function) Option {
+	return func(c *Calculator) error {
+		invalid := func(message string) error {
+			return InvalidOptionError{Option: "WithFunction", Message: message}
+		}
+
+		first, _ := utf8.DecodeRuneInString(fn.Name)
+		if fn.Name == "" || !isNameStart(first) {
+			return invalid("function name " + fmt.Sprintf("%q", fn.Name) + " must start with a letter or underscore")
+		}
+		for _, r := range fn.Name {
+			if !isNameRune(r) {
+				return invalid("function name " + fmt.Sprintf("%q", fn.Name) + " may only contain letters, digits and underscores")
+			}
+		}
+		if (fn.Apply == nil) == (fn.ApplyText == nil) {
+			return invalid("function " + fn.Name + " needs exactly one of Apply and ApplyText")
+		}
+		if fn.MinArgs < 0 {
+			return invalid("function " + fn.Name + " cannot take fewer than 0 arguments")
+		}
+		if fn.MaxArgs >= 0 && fn.MaxArgs < fn.MinArgs {
+			fn.MaxArgs = fn.MinArgs
+		}
+
+		functions := make(map[string]*Function, len(c.functions)+1)
+		for name, existing := range c.functions {
+			functions[name] = existing
+		}
+		functions[fn.Name] = &fn
+		c.functions = functions
+		return nil
+	}
+}
+
+// describeArgs returns how many arguments a function takes, for messages
+func (f *Function) describeArgs() string {
+	switch {
+	case f.MaxArgs == f.MinArgs && f.MinArgs == 1:
+		return "1 argument"
+	case f.MaxArgs == f.MinArgs:
+		return fmt.Sprintf("%d arguments", f.MinArgs)
+	case f.MaxArgs < 0 && f.MinArgs == 1:
+		return "at least 1 argument"
+	case f.MaxArgs < 0:
+		return fmt.Sprintf("at least %d arguments", f.MinArgs)
+	}
+	return fmt.Sprintf("%d to %d arguments", f.MinArgs, f.MaxArgs)
+}
+
+// checkCalls reports unknown functions, calls with the wrong number of
+// arguments, misplaced or unexpected text and unclosed calls
+func checkCalls(tokens []Token) ErrorList {
+	type call struct {
+		token Token
+		args  int
+	}
+	var errs ErrorList
+	var calls []call
+
+	for i, token := range tokens {
+		switch token.Type {
+		case FunctionToken:
+			if token.Function == nil {
+				errs = append(errs, ParseError{Message: "Unknown function " + token.Value, Position: token.Position, End: tokenEnd(token), Location: token.Location})
+			}
+			calls = append(calls, call{token: token})
+
+		case CommaToken:
+			if len(calls) > 0 {
+				calls[len(calls)-1].args++
+			}
+
+		case RightParenToken:
+			if len(calls) == 0 {
+				errs = append(errs, ParseError{Message: "Unexpected )", Position: token.Position, End: tokenEnd(token), Location: token.Location})
+				continue
+			}
+			open := calls[len(calls)-1]
+			calls = calls[:len(calls)-1]
+			if i == 0 || tokens[i-1].Type != LeftParenToken {
+				open.args++
+			}
+			fn := open.token.Function
+			if fn != nil && (open.args < fn.MinArgs || fn.MaxArgs >= 0 && open.args > fn.MaxArgs) {
+				errs = append(errs, ParseError{
+					Message:  fmt.Sprintf("%s takes %s, got %d", fn.Name, fn.describeArgs(), open.args),
+					Position: open.token.Position,
+					End:      tokenEnd(token),
+					Location: open.token.Location,
+				})
+			}
+
+		case TextToken:
+			before := i > 0 && (tokens[i-1].Type == LeftParenToken || tokens[i-1].Type == CommaToken)
+			after := i+1 < len(tokens) && (tokens[i+1].Type == RightParenToken || tokens[i+1].Type == CommaToken)
+			switch {
+			case len(calls) == 0 || !before || !after:
+				errs = append(errs, ParseError{Message: "Text is only allowed as a function argument", Position: token.Position, End: tokenEnd(token), Location: token.Location})
+			case calls[len(calls)-1].token.Function != nil && calls[len(calls)-1].token.Function.ApplyText == nil:
+				errs = append(errs, ParseError{Message: calls[len(calls)-1].token.Value + " does not take text arguments", Position: token.Position, End: tokenEnd(token), Location: token.Location})
+			}
+		}
+	}
+
+	for _, open := range calls {
+		errs = append(errs, ParseError{Message: "Missing ) after arguments to " + open.token.Value, Position: open.token.Position, End: tokenEnd(open.token), Location: open.token.Location})
+	}
+	return errs
+}
+
+// callFunction calls the function in token with the given arguments,
+// attributing any failure to the call
+func callFunction(token Token, args []Argument) (*big.Rat, error) {
+	fn := token.Function
+	if fn == nil {
+		return nil, ParseError{Message: "Unknown function " + token.Value, Position: token.Position, End: tokenEnd(token), Location: token.Location}
+	}
+
+	var result *big.Rat
+	var err error
+	if fn.ApplyText != nil {
+		result, err = fn.ApplyText(args...)
+	} else {
+		values := make([]*big.Rat, len(args))
+		for i, arg := range args {
+			values[i] = arg.Value
+		}
+		result, err = fn.Apply(values...)
+	}
+	if err == nil && result == nil {
+		err = errors.New("no result")
+	}
+	if err != nil {
+		return nil, CallError{Name: fn.Name, Position: token.Position, End: tokenEnd(token), Err: err}
+	}
+	return result, nil
+}
@@ -0,0 +1,238 @@
+package calculator
+
+import (
+	"context"
+	"math/big"
+)
+
+// Calculator evaluates expressions with its own operator and function tables,
+// accepted literal forms, limits and output format, so that programs needing
+// different syntax do not have to share package-level settings. A Calculator
+// is immutable once built by New and safe for concurrent use by multiple
+// goroutines. The package-level functions such as Calculate use a default
+// Calculator with the built-in operators, decimal, hexadecimal and binary
+// literals, and no limits.
+type Calculator struct {
+	operators operatorTable
+	functions map[string]*Function
+	literals  literalSet
+	limits    Limits
+	format    Format
+	observer  Observer
+	// variables reads names as VariableTokens, for Compile
+	variables bool
+}
+
+// Option configures a Calculator built by New
+type Option func(*Calculator) error
+
+// Format describes how Calculator.Format writes a result. The zero value
+// writes integers as they are and other values as reduced fractions, as
+// FormatRational does. The first setting present among Pattern, Locale, Radix
+// and Mixed is used.
+type Format struct {
+	// Pattern writes results with FormatPattern using Locale's symbols
+	Pattern string
+	// Locale writes results with FormatLocale when it has a Name
+	Locale Locale
+	// Radix writes results positionally as FormatRadixWithOptions does when
+	// its Base is set
+	Radix RadixOptions
+	// Mixed writes results as mixed numbers as FormatMixed does
+	Mixed bool
+}
+
+// literalSet records the accepted NumberTypes as bits
+type literalSet uint8
+
+// has reports whether numbers of type t are accepted
+func (s literalSet) has(t NumberType) bool {
+	return s&(1<<t) != 0
+}
+
+// builtinOperators are the operators performOperation implements
+var builtinOperators = []Operator{AdditionOp, SubtractionOp, MultiplicationOp, DivisionOp}
+
+// defaultCalculator backs the package-level functions
+var defaultCalculator = mustNew()
+
+// mustNew builds the default Calculator, whose options cannot fail
+func mustNew() *Calculator {
+	c, err := New()
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// New returns a Calculator with the built-in operators, no functions,
+// decimal, hexadecimal and binary literals, no limits and the default Format,
+// changed by opts in order. It returns an InvalidOptionError, InvalidBaseError or
+// InvalidPatternError for an option that cannot be applied.
+func New(opts ...Option) (*Calculator, error) {
+	c := &Calculator{
+		operators: newOperatorTable(builtinOperators),
+		literals:  1<<Decimal | 1<<Hexadecimal | 1<<Binary,
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	c.operators.index()
+	return c, nil
+}
+
+// WithOperators replaces the operator table, letting a Calculator drop
+// operators, change their precedence and associativity or add custom ones.
+// Each operator is checked as by WithOperator and may be listed only once.
+func WithOperators(ops ...Operator) Option {
+	return func(c *Calculator) error {
+		table := newOperatorTable(nil)
+		for _, op := range ops {
+			op, err := checkOperator("WithOperators", op)
+			if err != nil {
+				return err
+			}
+			if _, exists := table.bySpelling[op.String()]; exists {
+				return InvalidOptionError{Option: "WithOperators", Message: "operator " + op.String() + " is listed twice"}
+			}
+			table.add(op)
+		}
+		c.operators = table
+		return nil
+	}
+}
+
+// WithLiterals sets the forms of number literal accepted. Mixed enables mixed
+// numbers such as "2 1/3", as Options.MixedNumbers does. Other literals are
+// rejected with a ParseError.
+func WithLiterals(types ...NumberType) Option {
+	return func(c *Calculator) error {
+		var literals literalSet
+		for _, t := range types {
+			if t < Decimal || t > Mixed {
+				return InvalidOptionError{Option: "WithLiterals", Message: "unknown number type"}
+			}
+			literals |= 1 << t
+		}
+		if literals == 0 {
+			return InvalidOptionError{Option: "WithLiterals", Message: "at least one number type is required"}
+		}
+		c.literals = literals
+		return nil
+	}
+}
+
+// WithLimits bounds the work done on each expression
+func WithLimits(limits Limits) Option {
+	return func(c *Calculator) error {
+		if limits.MaxLength < 0 || limits.MaxTokens < 0 || limits.MaxDepth < 0 || limits.MaxBits < 0 || limits.MaxSteps < 0 {
+			return InvalidOptionError{Option: "WithLimits", Message: "limits cannot be negative"}
+		}
+		c.limits = limits
+		return nil
+	}
+}
+
+// WithFormat sets how Format writes results
+func WithFormat(format Format) Option {
+	return func(c *Calculator) error {
+		if format.Pattern != "" {
+			if _, err := ParsePattern(format.Pattern); err != nil {
+				return err
+			}
+		}
+		if format.Radix.Base != 0 && (format.Radix.Base < 2 || format.Radix.Base > 36) {
+			return InvalidBaseError{Base: format.Radix.Base}
+		}
+		c.format = format
+		return nil
+	}
+}
+
+// Calculate evaluates an expression and returns the exact result
+func (c *Calculator) Calculate(expression string) (*big.Rat, error) {
+	return c.CalculateContext(context.Background(), expression)
+}
+
+// CalculateContext evaluates an expression like Calculate, stopping with a
+// CanceledError once ctx is done
+func (c *Calculator) CalculateContext(ctx context.Context, expression string) (*big.Rat, error) {
+	return calculate[*big.Rat](ctx, c, expression, c.exactArithmetic())
+}
+
+// CalculateExpression evaluates an expression like the package-level
+// CalculateExpression, with the calculator's syntax and limits
+func (c *Calculator) CalculateExpression(expression string) (*Expression, error) {
+	expr, result, err := calculateExpression[*big.Rat](context.Background(), c, expression, c.exactArithmetic())
+	if expr == nil {
+		return nil, err
+	}
+
+	for _, token := range expr.Tokens {
+		if token.Type == NumberToken && token.Number != nil {
+			number := *token.Number
+			number.Value = new(big.Rat).Set(number.Value)
+			expr.Numbers = append(expr.Numbers, number)
+		}
+	}
+	expr.Result = result
+	return expr, err
+}
+
+// Tokenize converts an expression into tokens with the calculator's
+// operators and literal forms. Operator tokens carry their definitions, so
+// InfixToPostfix and EvaluatePostfix handle custom operators in them.
+func (c *Calculator) Tokenize(expression string) ([]Token, error) {
+	return c.tokenize(expression)
+}
+
+// Validate checks an expression without evaluating it, returning the first
+// error Calculate would report before evaluation
+func (c *Calculator) Validate(expression string) error {
+	if _, err := c.parseInput(expression); err != nil {
+		return withSource(err, expression)
+	}
+	return nil
+}
+
+// Format writes a result as configured by WithFormat
+func (c *Calculator) Format(result *big.Rat) (string, error) {
+	f := c.format
+	switch {
+	case f.Pattern != "":
+		return FormatPattern(result, f.Pattern, f.Locale)
+	case f.Locale.Name != "":
+		return FormatLocale(result, f.Locale)
+	case f.Radix.Base != 0:
+		return FormatRadixWithOptions(result, f.Radix)
+	case f.Mixed:
+		return FormatMixed(result), nil
+	}
+	return FormatRational(result), nil
+}
+
+// withOptions returns a copy of c accepting the syntax enabled in opts and
+// using its limits, for the package-level functions that take Options
+func (c *Calculator) withOptions(opts Options) *Calculator {
+	copied := *c
+	if opts.MixedNumbers {
+		copied.literals |= 1 << Mixed
+	}
+	copied.limits = opts.Limits
+	return &copied
+}
+
+// literalName returns the name of a form of number literal for messages
+func literalName(t NumberType) string {
+	switch t {
+	case Hexadecimal:
+		return "hexadecimal"
+	case Binary:
+		return "binary"
+	case Mixed:
+		return "mixed number"
+	}
+	return "decimal"
+}
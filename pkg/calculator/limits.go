@@ -0,0 +1,63 @@
+package calculator
+
+import "unicode/utf8"
+
+// Limits bounds the work done evaluating an expression, so that untrusted
+// input cannot exhaust memory or time: exact arithmetic lets a chain of
+// divisions build denominators with millions of digits. A zero field imposes
+// no limit. Each limit has its own error type, all matching ErrLimit.
+type Limits struct {
+	// MaxLength is the maximum expression length in runes
+	MaxLength int
+	// MaxTokens is the maximum number of numbers and operators
+	MaxTokens int
+	// MaxDepth is the maximum number of values on the evaluation stack
+	MaxDepth int
+	// MaxBits is the maximum bit length of the numerator or denominator of
+	// any literal or intermediate result
+	MaxBits int
+	// MaxSteps is the maximum number of operations applied
+	MaxSteps int
+}
+
+// DefaultLimits are generous limits for evaluating untrusted input: ample for
+// hand-written expressions while keeping every evaluation small and fast
+var DefaultLimits = Limits{
+	MaxLength: 10000,
+	MaxTokens: 2000,
+	MaxDepth:  256,
+	MaxBits:   1 << 16,
+	MaxSteps:  1000,
+}
+
+// checkLength enforces MaxLength
+func (l Limits) checkLength(expression string) error {
+	if l.MaxLength <= 0 || len(expression) <= l.MaxLength {
+		return nil
+	}
+	if n := utf8.RuneCountInString(expression); n > l.MaxLength {
+		return InputTooLongError{Length: n, Limit: l.MaxLength}
+	}
+	return nil
+}
+
+// checkTokens enforces MaxTokens
+func (l Limits) checkTokens(tokens []Token) error {
+	if l.MaxTokens <= 0 || len(tokens) <= l.MaxTokens {
+		return nil
+	}
+	over := tokens[l.MaxTokens]
+	return TooManyTokensError{Limit: l.MaxTokens, Position: over.Position, End: tokenEnd(over)}
+}
+
+// checkBits enforces MaxBits for a value produced at a token
+func (l Limits) checkBits(bits int, token Token) error {
+	if l.MaxBits <= 0 || bits <= l.MaxBits {
+		return nil
+	}
+	end := token.Position + 1
+	if token.Type != OperatorToken {
+		end = tokenEnd(token)
+	}
+	return NumberTooLargeError{Bits: bits, Limit: l.MaxBits, Position: token.Position, End: end}
+}
@@ -0,0 +1,75 @@
+package calculator
+
+import (
+	"math/big"
+	"sort"
+)
+
+// Locale describes the symbols and default pattern used to format numbers for
+// a region. Empty fields fall back to "." for the decimal separator, "," for
+// grouping, "-" for the minus sign and DefaultPattern.
+type Locale struct {
+	Name             string
+	DecimalSeparator string
+	GroupSeparator   string
+	MinusSign        string
+	Pattern          string
+}
+
+// localePresets holds the built-in locales by name
+var localePresets = map[string]Locale{
+	"en-US": {Name: "en-US", DecimalSeparator: ".", GroupSeparator: ","},
+	"en-GB": {Name: "en-GB", DecimalSeparator: ".", GroupSeparator: ","},
+	"en-IN": {Name: "en-IN", DecimalSeparator: ".", GroupSeparator: ",", Pattern: "#,##,##0.###"},
+	"hi-IN": {Name: "hi-IN", DecimalSeparator: ".", GroupSeparator: ",", Pattern: "#,##,##0.###"},
+	"de-DE": {Name: "de-DE", DecimalSeparator: ",", GroupSeparator: "."},
+	"de-CH": {Name: "de-CH", DecimalSeparator: ".", GroupSeparator: "\u2019"},
+	"es-ES": {Name: "es-ES", DecimalSeparator: ",", GroupSeparator: "."},
+	"it-IT": {Name: "it-IT", DecimalSeparator: ",", GroupSeparator: "."},
+	"nl-NL": {Name: "nl-NL", DecimalSeparator: ",", GroupSeparator: "."},
+	"pt-BR": {Name: "pt-BR", DecimalSeparator: ",", GroupSeparator: "."},
+	"fr-FR": {Name: "fr-FR", DecimalSeparator: ",", GroupSeparator: "\u202f"},
+	"sv-SE": {Name: "sv-SE", DecimalSeparator: ",", GroupSeparator: "\u00a0", MinusSign: "\u2212"},
+	"ja-JP": {Name: "ja-JP", DecimalSeparator: ".", GroupSeparator: ","},
+	"zh-CN": {Name: "zh-CN", DecimalSeparator: ".", GroupSeparator: ","},
+}
+
+// LookupLocale returns the built-in locale with the given name, e.g. "de-DE"
+func LookupLocale(name string) (Locale, bool) {
+	loc, ok := localePresets[name]
+	return loc, ok
+}
+
+// LocaleNames returns the names of the built-in locales in sorted order
+func LocaleNames() []string {
+	names := make([]string, 0, len(localePresets))
+	for name := range localePresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FormatLocale formats a rational number with the locale's default pattern
+func FormatLocale(result *big.Rat, loc Locale) (string, error) {
+	pattern := loc.Pattern
+	if pattern == "" {
+		pattern = DefaultPattern
+	}
+	return FormatPattern(result, pattern, loc)
+}
+
+// symbols returns the locale's separators and minus sign with defaults applied
+func (l Locale) symbols() (decimal, group, minus string) {
+	decimal, group, minus = l.DecimalSeparator, l.GroupSeparator, l.MinusSign
+	if decimal == "" {
+		decimal = "."
+	}
+	if group == "" {
+		group = ","
+	}
+	if minus == "" {
+		minus = "-"
+	}
+	return decimal, group, minus
+}
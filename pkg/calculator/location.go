@@ -0,0 +1,70 @@
+package calculator
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// Location is a place in an expression. Token and error positions count runes,
+// so Rune always equals the Position beside it; Offset gives the byte offset
+// for slicing the original string. Lines and columns start at 1, columns
+// count runes, and only '\n' starts a new line.
+type Location struct {
+	Offset int
+	Rune   int
+	Line   int
+	Column int
+}
+
+// String returns the location as "line:column"
+func (l Location) String() string {
+	return fmt.Sprintf("%d:%d", l.Line, l.Column)
+}
+
+// Locate returns the location of the rune at the given rune offset. Offsets
+// past the end locate the end of the expression.
+func Locate(expression string, position int) Location {
+	return newLocator(expression).locate(position)
+}
+
+// locator maps rune offsets in an expression to locations
+type locator struct {
+	// offsets holds the byte offset of each rune, plus the length of the string
+	offsets []int
+	// lineStarts holds the rune offset at which each line begins
+	lineStarts []int
+}
+
+// newLocator indexes the runes and lines of an expression
+func newLocator(expression string) locator {
+	l := locator{
+		offsets:    make([]int, 0, utf8.RuneCountInString(expression)+1),
+		lineStarts: []int{0},
+	}
+	for offset, r := range expression {
+		l.offsets = append(l.offsets, offset)
+		if r == '\n' {
+			l.lineStarts = append(l.lineStarts, len(l.offsets))
+		}
+	}
+	l.offsets = append(l.offsets, len(expression))
+	return l
+}
+
+// locate returns the location of a rune offset
+func (l locator) locate(position int) Location {
+	position = min(max(position, 0), len(l.offsets)-1)
+
+	// The line is the last one starting at or before the position
+	line := sort.Search(len(l.lineStarts), func(i int) bool {
+		return l.lineStarts[i] > position
+	}) - 1
+
+	return Location{
+		Offset: l.offsets[position],
+		Rune:   position,
+		Line:   line + 1,
+		Column: position - l.lineStarts[line] + 1,
+	}
+}
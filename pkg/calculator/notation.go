@@ -0,0 +1,86 @@
+package calculator
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// FormatScientific formats a result in scientific notation rounded to the
+// given number of significant digits, e.g. "1.234e-40". Rounding is exact,
+// with ties rounded away from zero.
+func FormatScientific(result *big.Rat, digits int) string {
+	return formatExponential(result, digits, 1)
+}
+
+// FormatEngineering formats a result like FormatScientific but with an
+// exponent that is a multiple of three, e.g. "12.3e+3"
+func FormatEngineering(result *big.Rat, digits int) string {
+	return formatExponential(result, digits, 3)
+}
+
+// formatExponential renders a result as mantissa and exponent, where the
+// exponent is constrained to a multiple of step
+func formatExponential(result *big.Rat, digits, step int) string {
+	if digits < 1 {
+		digits = 1
+	}
+
+	mantissa, exponent := significantDigits(result, digits)
+
+	// Choose the exponent and how many digits sit before the decimal point
+	shown := exponent
+	if step > 1 {
+		shown = floorDiv(exponent, step) * step
+	}
+	intDigits := exponent - shown + 1
+	if len(mantissa) < intDigits {
+		mantissa += strings.Repeat("0", intDigits-len(mantissa))
+	}
+
+	var sb strings.Builder
+	if result.Sign() < 0 {
+		sb.WriteByte('-')
+	}
+	sb.WriteString(mantissa[:intDigits])
+	if intDigits < len(mantissa) {
+		sb.WriteByte('.')
+		sb.WriteString(mantissa[intDigits:])
+	}
+	sb.WriteByte('e')
+	if shown >= 0 {
+		sb.WriteByte('+')
+	}
+	sb.WriteString(strconv.Itoa(shown))
+	return sb.String()
+}
+
+// significantDigits rounds |x| to n significant digits and returns them as a
+// string of exactly n digits together with the decimal exponent of the first
+func significantDigits(x *big.Rat, n int) (string, int) {
+	if x.Sign() == 0 {
+		return strings.Repeat("0", n), 0
+	}
+
+	exponent := decimalExponent(x)
+	scaled := new(big.Rat).Abs(x)
+	scaled.Mul(scaled, pow10(n-1-exponent))
+	m := roundHalfAwayFromZero(scaled)
+
+	// Rounding may carry into a new digit, e.g. 9.99 -> 10.0
+	digits := m.String()
+	if len(digits) > n {
+		exponent++
+		digits = digits[:n]
+	}
+	return digits, exponent
+}
+
+// floorDiv divides rounding towards negative infinity
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
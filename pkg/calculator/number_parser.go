@@ -29,9 +29,61 @@ func ParseDecimal(s string) (*big.Rat, error) {
 
 // ParseHexadecimal parses a hexadecimal number string to exact rational
 func ParseHexadecimal(s string) (*big.Rat, error) {
+	return parsePrefixedInteger(s, 'x', 16, "hex")
+}
+
+// ParseBinary parses a binary number string prefixed with 0b to exact rational
+func ParseBinary(s string) (*big.Rat, error) {
+	return parsePrefixedInteger(s, 'b', 2, "binary")
+}
+
+// ParseNumber parses a number literal of any supported format, recording its
+// original text and the format it was written in
+func ParseNumber(s string) (*Number, error) {
+	numberType := literalType(s)
+
+	var value *big.Rat
+	var err error
+	switch numberType {
+	case Hexadecimal:
+		value, err = ParseHexadecimal(s)
+	case Binary:
+		value, err = ParseBinary(s)
+	case Mixed:
+		value, err = ParseMixedNumber(s)
+	default:
+		value, err = ParseDecimal(s)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Number{Value: value, Original: s, Type: numberType}, nil
+}
+
+// literalType classifies a number literal by its prefix
+func literalType(s string) NumberType {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "-")
+	if strings.ContainsAny(s, " \t") {
+		return Mixed
+	}
+	if len(s) >= 2 && s[0] == '0' {
+		switch s[1] {
+		case 'x', 'X':
+			return Hexadecimal
+		case 'b', 'B':
+			return Binary
+		}
+	}
+	return Decimal
+}
+
+// parsePrefixedInteger parses an optionally negative integer written with a
+// 0<letter> prefix in the given base
+func parsePrefixedInteger(s string, letter byte, base int, name string) (*big.Rat, error) {
 	s = strings.TrimSpace(s)
 	if s == "" {
-		return nil, ParseError{Message: "Empty hex number", Position: 0}
+		return nil, ParseError{Message: "Empty " + name + " number", Position: 0}
 	}
 
 	// Handle negative sign
@@ -41,22 +93,23 @@ func ParseHexadecimal(s string) (*big.Rat, error) {
 		s = s[1:]
 	}
 
-	// Check for hex prefix
-	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
-		return nil, ParseError{Message: "Hex number must start with 0x", Position: 0}
+	// Check for prefix
+	prefix := "0" + string(letter)
+	if !strings.HasPrefix(strings.ToLower(s), prefix) {
+		return nil, ParseError{Message: capitalize(name) + " number must start with " + prefix, Position: 0}
 	}
 
-	// Remove 0x prefix
-	hexDigits := s[2:]
-	if hexDigits == "" {
-		return nil, ParseError{Message: "No hex digits after 0x", Position: 2}
+	// Remove prefix
+	digits := s[2:]
+	if digits == "" {
+		return nil, ParseError{Message: "No " + name + " digits after " + prefix, Position: 2}
 	}
 
-	// Parse as base 16 using big.Int to handle large numbers
+	// Parse using big.Int to handle large numbers
 	bigInt := new(big.Int)
-	_, ok := bigInt.SetString(hexDigits, 16)
+	_, ok := bigInt.SetString(digits, base)
 	if !ok {
-		return nil, ParseError{Message: "Invalid hex digits", Position: 2}
+		return nil, ParseError{Message: "Invalid " + name + " digits", Position: 2}
 	}
 
 	if negative {
@@ -68,3 +121,11 @@ func ParseHexadecimal(s string) (*big.Rat, error) {
 	rat.SetInt(bigInt)
 	return rat, nil
 }
+
+// capitalize upper-cases the first letter of an ASCII word
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,85 @@
+package calculator
+
+import (
+	"math/big"
+	"time"
+)
+
+// Observer receives events from a Calculator's tokenizer, parser and
+// evaluator, for metrics and tracing. It is installed with WithObserver and
+// called synchronously on the goroutine doing the work, so a Calculator shared
+// between goroutines needs an Observer that is safe for concurrent use.
+// Calculators without an Observer do no extra work.
+//
+// Calculate and Explain call Tokenized, Parsed when tokenizing succeeded,
+// then Evaluated; an evaluation whose context is already done is only
+// reported to Evaluated. Compile and Validate call only Tokenized and Parsed, and
+// Program.Eval calls only Evaluated.
+type Observer interface {
+	// Tokenized is called after an expression is tokenized, with the tokens
+	// or the error. Blank and overlong expressions are reported here.
+	Tokenized(expression string, tokens []Token, err error)
+	// Parsed is called after the tokens are checked and put in postfix
+	// order, with the postfix tokens or the error
+	Parsed(expression string, postfix []Token, err error)
+	// Evaluated is called once per evaluation when it finishes, whether it
+	// succeeded or failed
+	Evaluated(evaluation Evaluation)
+}
+
+// OperationObserver is an Observer that is also told of each operation the
+// evaluator performs, with its exact operands and result. Operations are only
+// reported in exact evaluation, and cost an allocation each when observed.
+type OperationObserver interface {
+	Observer
+	Operation(step Step)
+}
+
+// Evaluation describes a finished evaluation for Observer.Evaluated
+type Evaluation struct {
+	Expression string
+	// Tokens is the number of tokens, or 0 if the expression did not parse
+	Tokens int
+	// Duration covers tokenizing, parsing and evaluating for Calculate, and
+	// evaluating only for Program.Eval
+	Duration time.Duration
+	// ResultBits is the larger bit length of the result's numerator and
+	// denominator, as limited by Limits.MaxBits
+	ResultBits int
+	// Err is the error returned to the caller, and Code its ErrorCode; both
+	// are empty on success
+	Err  error
+	Code ErrorCode
+}
+
+// WithObserver installs an Observer, or removes it when o is nil
+func WithObserver(o Observer) Option {
+	return func(c *Calculator) error {
+		c.observer = o
+		return nil
+	}
+}
+
+// exactArithmetic returns the arithmetic for exact evaluation, reporting
+// each operation to an OperationObserver
+func (c *Calculator) exactArithmetic() arithmetic[*big.Rat] {
+	if observer, ok := c.observer.(OperationObserver); ok {
+		return &tracingArithmetic{record: observer.Operation}
+	}
+	return ratArithmetic{}
+}
+
+// evaluated reports a finished evaluation to the observer
+func (c *Calculator) evaluated(start time.Time, expression string, expr *Expression, bits int, err error) {
+	evaluation := Evaluation{
+		Expression: expression,
+		Duration:   time.Since(start),
+		ResultBits: bits,
+		Err:        err,
+		Code:       CodeOf(err),
+	}
+	if expr != nil {
+		evaluation.Tokens = len(expr.Tokens)
+	}
+	c.observer.Evaluated(evaluation)
+}
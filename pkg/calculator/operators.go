@@ -0,0 +1,200 @@
+package calculator
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// operatorTable holds a Calculator's operators by spelling, with the
+// spellings ordered longest first so that the tokenizer finds the longest
+// match
+type operatorTable struct {
+	bySpelling map[string]*Operator
+	spellings  []string
+	// alphabet holds every rune used in a spelling, for the character check
+	alphabet string
+}
+
+// newOperatorTable returns a table holding ops
+func newOperatorTable(ops []Operator) operatorTable {
+	table := operatorTable{bySpelling: make(map[string]*Operator, len(ops))}
+	for _, op := range ops {
+		table.add(op)
+	}
+	return table
+}
+
+// add stores op, replacing any operator spelled the same way
+func (t operatorTable) add(op Operator) {
+	t.bySpelling[op.String()] = &op
+}
+
+// index orders the spellings and collects their runes once all operators
+// are added
+func (t *operatorTable) index() {
+	t.spellings = t.spellings[:0]
+	var alphabet strings.Builder
+	for spelling := range t.bySpelling {
+		t.spellings = append(t.spellings, spelling)
+		alphabet.WriteString(spelling)
+	}
+	sort.Slice(t.spellings, func(i, j int) bool {
+		li, lj := utf8.RuneCountInString(t.spellings[i]), utf8.RuneCountInString(t.spellings[j])
+		if li != lj {
+			return li > lj
+		}
+		return t.spellings[i] < t.spellings[j]
+	})
+	t.alphabet = alphabet.String()
+}
+
+// lookup returns the operator a token stands for: its own Operator, or for
+// tokens built by hand the one its Value spells. Unknown operators are
+// returned as the zero Operator and fail when evaluated.
+func (t operatorTable) lookup(token Token) Operator {
+	if token.Operator != nil {
+		return *token.Operator
+	}
+	if op, ok := t.bySpelling[token.Value]; ok {
+		return *op
+	}
+	return Operator{}
+}
+
+// isPrefix reports whether token is a prefix operator
+func (t operatorTable) isPrefix(token Token) bool {
+	return token.Type == OperatorToken && t.lookup(token).unary()
+}
+
+// match returns the longest operator spelled at runes[i] and the position
+// after it. Spellings longer than one rune that end in a letter or digit only
+// match a whole word, so "mod" does not match the start of "modulo".
+func (t operatorTable) match(runes []rune, i int) (*Operator, int, bool) {
+	for _, spelling := range t.spellings {
+		end := i
+		for _, r := range spelling {
+			if end >= len(runes) || runes[end] != r {
+				end = -1
+				break
+			}
+			end++
+		}
+		if end < 0 {
+			continue
+		}
+		last := runes[end-1]
+		if end-i > 1 && isNameRune(last) && end < len(runes) && isNameRune(runes[end]) {
+			continue
+		}
+		return t.bySpelling[spelling], end, true
+	}
+	return nil, i, false
+}
+
+// spellsAt reports whether an operator is spelled at runes[i]
+func (t operatorTable) spellsAt(runes []rune, i int) bool {
+	_, _, ok := t.match(runes, i)
+	return ok
+}
+
+// spells reports whether ch appears in any operator's spelling
+func (t operatorTable) spells(ch rune) bool {
+	return strings.ContainsRune(t.alphabet, ch)
+}
+
+// WithOperator adds a custom operator, or replaces the operator spelled the
+// same way. Operators other than the built-in '+', '-', 'x' and '/' need an
+// Apply function taking Arity operands. A spelling may not clash with number
+// literals: it cannot start with a digit, '.' or '-', or with the 'x' or 'b'
+// of the 0x and 0b prefixes, or be made only of hexadecimal digits, such as
+// "e" or "add", which a hexadecimal literal would take in. It also cannot
+// contain whitespace or the parentheses, commas and quotes of function calls.
+func WithOperator(op Operator) Option {
+	return func(c *Calculator) error {
+		op, err := checkOperator("WithOperator", op)
+		if err != nil {
+			return err
+		}
+		c.operators.add(op)
+		return nil
+	}
+}
+
+// checkOperator validates an operator for option, filling in its default
+// arity
+func checkOperator(option string, op Operator) (Operator, error) {
+	invalid := func(message string) (Operator, error) {
+		return Operator{}, InvalidOptionError{Option: option, Message: message}
+	}
+
+	if op.Name != "" && op.Symbol != 0 {
+		return invalid("operator " + op.String() + " has both a Symbol and a Name")
+	}
+	if op.Symbol == 0 && op.Name == "" {
+		return invalid("operator needs a Symbol or a Name")
+	}
+	spelling := op.String()
+	if op.Arity == 0 {
+		op.Arity = 2
+	}
+	if op.Arity != 1 && op.Arity != 2 {
+		return invalid("operator " + spelling + " must take 1 or 2 operands")
+	}
+	if op.Associativity != Left && op.Associativity != Right {
+		return invalid("operator " + spelling + " has unknown associativity")
+	}
+
+	if isBuiltinOperator(spelling) {
+		if op.Apply == nil && op.Arity != 2 {
+			return invalid("operator " + spelling + " needs an Apply function to take 1 operand")
+		}
+		return op, nil
+	}
+	if op.Apply == nil {
+		return invalid("operator " + spelling + " has no Apply function")
+	}
+
+	first, _ := utf8.DecodeRuneInString(spelling)
+	switch {
+	case strings.IndexFunc(spelling, unicode.IsSpace) >= 0:
+		return invalid("operator " + spelling + " contains whitespace")
+	case strings.ContainsAny(spelling, `(),"`):
+		return invalid("operator " + spelling + " clashes with function call syntax")
+	case isDigit(first) || first == '.':
+		return invalid("operator " + spelling + " would be read as a number")
+	case first == '-':
+		return invalid("operator " + spelling + " would be read as a negative number")
+	case strings.ContainsRune("xXbB", first):
+		return invalid("operator " + spelling + " conflicts with the " + string(first) + " in the 0" + string(first) + " prefix")
+	case strings.IndexFunc(spelling, func(r rune) bool { return !isHexDigit(r) }) < 0:
+		return invalid("operator " + spelling + " would be read as hexadecimal digits")
+	}
+	return op, nil
+}
+
+// applyOperator calls a custom operator's Apply, attributing any failure to
+// the operator's position
+func applyOperator(op *Operator, token Token, operands ...*big.Rat) (*big.Rat, error) {
+	result, err := op.Apply(operands...)
+	if err == nil && result == nil {
+		err = errors.New("no result")
+	}
+	if err != nil {
+		return nil, CallError{Name: op.String(), Position: token.Position, End: tokenEnd(token), Err: err}
+	}
+	return result, nil
+}
+
+// isBuiltinOperator reports whether performOperation implements spelling
+func isBuiltinOperator(spelling string) bool {
+	for _, op := range builtinOperators {
+		if op.String() == spelling {
+			return true
+		}
+	}
+	return false
+}
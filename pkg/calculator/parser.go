@@ -2,33 +2,61 @@ package calculator
 
 // ParseExpression converts tokens into validated expression structure
 func ParseExpression(tokens []Token) (*Expression, error) {
+	return defaultCalculator.parseTokens(tokens)
+}
+
+// parseTokens converts tokens into validated expression structure, ordering
+// operators by the calculator's table
+func (c *Calculator) parseTokens(tokens []Token) (*Expression, error) {
 	if len(tokens) == 0 {
 		return nil, EmptyExpressionError{}
 	}
 
-	// Basic validation: must start and end with numbers
-	if tokens[0].Type != NumberToken {
-		return nil, ParseError{Message: "Expression must start with a number", Position: tokens[0].Position}
+	// Malformed calls are reported first, as ValidateAll orders them, so that
+	// "tax(" is a missing ) rather than a missing number
+	if errs := checkCalls(tokens); len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	// Basic validation: must start and end with numbers, though prefix
+	// operators and function calls may come first and calls may end
+	if first := c.roleOf(tokens[0]); first != roleValue && first != rolePrefix {
+		return nil, ParseError{Message: "Expression must start with a number", Position: tokens[0].Position, End: tokenEnd(tokens[0]), Location: tokens[0].Location}
 	}
-	if tokens[len(tokens)-1].Type != NumberToken {
-		return nil, ParseError{Message: "Expression must end with a number", Position: tokens[len(tokens)-1].Position}
+	if last := c.roleOf(tokens[len(tokens)-1]); last != roleValue && last != roleClose {
+		return nil, ParseError{Message: "Expression must end with a number", Position: tokens[len(tokens)-1].Position, End: tokenEnd(tokens[len(tokens)-1]), Location: tokens[len(tokens)-1].Location}
 	}
 
-	// Validate alternating pattern: number op number op number...
+	// Validate alternating pattern: number op number op number..., where
+	// each number may follow any number of prefix operators and may be a
+	// function call
+	expectNumber := true
 	for i, token := range tokens {
-		if i%2 == 0 { // Even positions should be numbers
-			if token.Type != NumberToken {
-				return nil, ParseError{Message: "Expected number", Position: token.Position}
+		switch c.roleOf(token) {
+		case roleValue:
+			if !expectNumber {
+				return nil, ParseError{Message: "Expected operator", Position: token.Position, End: tokenEnd(token), Location: token.Location}
 			}
-		} else { // Odd positions should be operators
-			if token.Type != OperatorToken {
-				return nil, ParseError{Message: "Expected operator", Position: token.Position}
+			expectNumber = false
+		case rolePrefix:
+			if !expectNumber {
+				return nil, ParseError{Message: "Expected operator", Position: token.Position, End: tokenEnd(token), Location: token.Location}
 			}
+		case roleInfix:
+			if expectNumber {
+				return nil, ParseError{Message: "Expected number", Position: token.Position, End: tokenEnd(token), Location: token.Location}
+			}
+			expectNumber = true
+		case roleClose:
+			if expectNumber && tokens[i-1].Type != LeftParenToken {
+				return nil, ParseError{Message: "Expected number", Position: token.Position, End: tokenEnd(token), Location: token.Location}
+			}
+			expectNumber = false
 		}
 	}
 
 	// Convert to postfix notation for evaluation
-	postfixTokens, err := InfixToPostfix(tokens)
+	postfixTokens, err := c.infixToPostfix(tokens)
 	if err != nil {
 		return nil, err
 	}
@@ -42,23 +70,75 @@ func ParseExpression(tokens []Token) (*Expression, error) {
 
 // InfixToPostfix converts infix notation to postfix using Shunting Yard algorithm
 func InfixToPostfix(tokens []Token) ([]Token, error) {
+	return defaultCalculator.infixToPostfix(tokens)
+}
+
+// infixToPostfix converts infix notation to postfix with the calculator's
+// operator precedence and associativity. Prefix operators wait on the stack
+// for their operand and are applied once an operator of lower precedence
+// arrives. A function call is emitted after its arguments, with Args set to
+// their number.
+func (c *Calculator) infixToPostfix(tokens []Token) ([]Token, error) {
 	output := []Token{}
 	operatorStack := []Token{}
+	// args counts the arguments of each open call
+	args := []int{}
 
-	for _, token := range tokens {
+	for i, token := range tokens {
 		switch token.Type {
-		case NumberToken:
+		case NumberToken, VariableToken, TextToken:
 			output = append(output, token)
 
+		case FunctionToken:
+			operatorStack = append(operatorStack, token)
+
+		case LeftParenToken:
+			operatorStack = append(operatorStack, token)
+			args = append(args, 0)
+
+		case CommaToken, RightParenToken:
+			// Apply the operators within the argument
+			for len(operatorStack) > 0 && operatorStack[len(operatorStack)-1].Type != LeftParenToken {
+				output = append(output, operatorStack[len(operatorStack)-1])
+				operatorStack = operatorStack[:len(operatorStack)-1]
+			}
+			if len(operatorStack) == 0 || len(args) == 0 {
+				return nil, ParseError{Message: "Unexpected " + token.Value, Position: token.Position, End: tokenEnd(token), Location: token.Location}
+			}
+			if token.Type == CommaToken || tokens[i-1].Type != LeftParenToken {
+				args[len(args)-1]++
+			}
+			if token.Type == CommaToken {
+				continue
+			}
+
+			// Drop the parenthesis and emit the call with its argument count
+			operatorStack = operatorStack[:len(operatorStack)-1]
+			call := operatorStack[len(operatorStack)-1]
+			operatorStack = operatorStack[:len(operatorStack)-1]
+			call.Args = args[len(args)-1]
+			args = args[:len(args)-1]
+			output = append(output, call)
+
 		case OperatorToken:
-			op := OperatorMap[rune(token.Value[0])]
+			op := c.operators.lookup(token)
+			if op.unary() {
+				operatorStack = append(operatorStack, token)
+				continue
+			}
 
-			// Pop operators with higher or equal precedence
+			// Pop operators with higher precedence, or equal precedence when
+			// the incoming operator is left-associative, within the current
+			// argument
 			for len(operatorStack) > 0 {
 				stackTop := operatorStack[len(operatorStack)-1]
-				stackOp := OperatorMap[rune(stackTop.Value[0])]
+				if stackTop.Type != OperatorToken {
+					break
+				}
+				stackOp := c.operators.lookup(stackTop)
 
-				if stackOp.Precedence >= op.Precedence {
+				if stackOp.Precedence > op.Precedence ||
+					(stackOp.Precedence == op.Precedence && op.Associativity == Left) {
 					output = append(output, stackTop)
 					operatorStack = operatorStack[:len(operatorStack)-1]
 				} else {
@@ -81,11 +161,41 @@ func InfixToPostfix(tokens []Token) ([]Token, error) {
 
 // ValidateExpression validates expression format without performing calculation
 func ValidateExpression(expression string) error {
-	tokens, err := Tokenize(expression)
-	if err != nil {
-		return err
-	}
+	return defaultCalculator.Validate(expression)
+}
+
+// role is the part a token plays in the alternation of numbers and operators
+type role int
 
-	_, err = ParseExpression(tokens)
-	return err
+const (
+	// roleValue is a number, variable or text
+	roleValue role = iota
+	// rolePrefix is a prefix operator or function name, preceding a value
+	rolePrefix
+	// roleInfix is a binary operator or argument comma, between values
+	roleInfix
+	// roleOpen is the parenthesis opening a call's arguments
+	roleOpen
+	// roleClose is the parenthesis closing a call, completing a value
+	roleClose
+)
+
+// roleOf returns the part a token plays
+func (c *Calculator) roleOf(token Token) role {
+	switch token.Type {
+	case FunctionToken:
+		return rolePrefix
+	case LeftParenToken:
+		return roleOpen
+	case RightParenToken:
+		return roleClose
+	case CommaToken:
+		return roleInfix
+	case OperatorToken:
+		if c.operators.isPrefix(token) {
+			return rolePrefix
+		}
+		return roleInfix
+	}
+	return roleValue
 }
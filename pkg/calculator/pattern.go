@@ -0,0 +1,274 @@
+package calculator
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// DefaultPattern is the pattern used when a locale does not define its own
+const DefaultPattern = "#,##0.###"
+
+// Pattern is a compiled number format pattern in the style of ICU
+// DecimalFormat and Excel, e.g. "#,##0.00;(#,##0.00)".
+//
+// A pattern has up to three sections separated by ';' for positive, negative
+// and zero values. Each section is an optional prefix, a number part made of
+// '#', '0', ',' and '.', and an optional suffix. In the number part '0' is a
+// required digit, '#' an optional digit, ',' marks grouping and '.' the
+// decimal point. The negative and zero sections only contribute their prefix
+// and suffix, or may be plain text; digits always follow the positive
+// section. Without a negative section negative values are prefixed with a
+// minus sign. Prefixes and suffixes may contain quoted literals ('text'), '%'
+// to multiply by 100 and '‰' to multiply by 1000.
+type Pattern struct {
+	source     string
+	sections   []affixes
+	minInt     int
+	minFrac    int
+	maxFrac    int
+	primary    int
+	secondary  int
+	multiplier int64
+}
+
+// affixes holds the literal text around the digits of one pattern section.
+// A section without a number part is printed as literal text only.
+type affixes struct {
+	prefix  string
+	suffix  string
+	literal bool
+}
+
+// ParsePattern compiles a number format pattern
+func ParsePattern(pattern string) (*Pattern, error) {
+	sections := splitSections(pattern)
+	if len(sections) > 3 {
+		return nil, InvalidPatternError{Pattern: pattern, Message: "at most three sections are allowed"}
+	}
+
+	p := &Pattern{source: pattern, multiplier: 1}
+	for i, section := range sections {
+		prefix, number, suffix, err := splitSection(section)
+		if err != nil {
+			return nil, InvalidPatternError{Pattern: pattern, Message: err.Error()}
+		}
+		if i == 0 {
+			if err := p.parseNumber(number); err != nil {
+				return nil, InvalidPatternError{Pattern: pattern, Message: err.Error()}
+			}
+		}
+
+		prefix, prefixMul := unquoteAffix(prefix)
+		suffix, suffixMul := unquoteAffix(suffix)
+		if i == 0 {
+			p.multiplier = prefixMul * suffixMul
+		}
+		p.sections = append(p.sections, affixes{prefix: prefix, suffix: suffix, literal: number == ""})
+	}
+
+	return p, nil
+}
+
+// String returns the source text of the pattern
+func (p *Pattern) String() string {
+	return p.source
+}
+
+// Format renders a rational number with the pattern using the locale's symbols
+func (p *Pattern) Format(result *big.Rat, loc Locale) string {
+	decimalSep, groupSep, minus := loc.symbols()
+
+	value := new(big.Rat).Abs(result)
+	value.Mul(value, new(big.Rat).SetInt64(p.multiplier))
+
+	// Round to the maximum number of fraction digits and split the digits
+	scaled := roundHalfAwayFromZero(new(big.Rat).Mul(value, pow10(p.maxFrac)))
+	digits := scaled.String()
+	if len(digits) <= p.maxFrac {
+		digits = strings.Repeat("0", p.maxFrac-len(digits)+1) + digits
+	}
+	intDigits := digits[:len(digits)-p.maxFrac]
+	fracDigits := digits[len(digits)-p.maxFrac:]
+
+	// Drop optional trailing fraction digits and pad required integer digits
+	for len(fracDigits) > p.minFrac && fracDigits[len(fracDigits)-1] == '0' {
+		fracDigits = fracDigits[:len(fracDigits)-1]
+	}
+	intDigits = strings.TrimLeft(intDigits, "0")
+	if len(intDigits) < p.minInt {
+		intDigits = strings.Repeat("0", p.minInt-len(intDigits)) + intDigits
+	}
+	if intDigits == "" && fracDigits == "" {
+		intDigits = "0"
+	}
+
+	var number strings.Builder
+	number.WriteString(p.group(intDigits, groupSep))
+	if fracDigits != "" {
+		number.WriteString(decimalSep)
+		number.WriteString(fracDigits)
+	}
+
+	// Select the section by the sign of the rounded value
+	section := p.sections[0]
+	sign := ""
+	switch {
+	case scaled.Sign() == 0:
+		if len(p.sections) > 2 {
+			section = p.sections[2]
+		}
+	case result.Sign() < 0:
+		if len(p.sections) > 1 {
+			section = p.sections[1]
+		} else {
+			sign = minus
+		}
+	}
+
+	if section.literal {
+		return section.prefix
+	}
+	return sign + section.prefix + number.String() + section.suffix
+}
+
+// group inserts grouping separators into a string of integer digits
+func (p *Pattern) group(digits, sep string) string {
+	if p.primary <= 0 || len(digits) <= p.primary {
+		return digits
+	}
+
+	size := p.primary
+	groups := []string{}
+	for len(digits) > size {
+		groups = append([]string{digits[len(digits)-size:]}, groups...)
+		digits = digits[:len(digits)-size]
+		if p.secondary > 0 {
+			size = p.secondary
+		}
+	}
+	groups = append([]string{digits}, groups...)
+	return strings.Join(groups, sep)
+}
+
+// parseNumber reads digit counts and grouping sizes from the number part
+func (p *Pattern) parseNumber(number string) error {
+	intPart, fracPart, hasPoint := strings.Cut(number, ".")
+	if hasPoint && strings.ContainsAny(fracPart, ".,") {
+		return errors.New("separators are not allowed in fraction digits")
+	}
+	if !strings.ContainsAny(intPart+fracPart, "#0") {
+		return errors.New("pattern has no digits")
+	}
+
+	// Grouping sizes come from the distances between the last separators
+	groups := strings.Split(intPart, ",")
+	if len(groups) > 1 {
+		p.primary = len(groups[len(groups)-1])
+		if p.primary == 0 {
+			return errors.New("grouping separator must be followed by digits")
+		}
+		if len(groups) > 2 {
+			p.secondary = len(groups[len(groups)-2])
+			if p.secondary == p.primary {
+				p.secondary = 0
+			}
+		}
+	}
+
+	p.minInt = strings.Count(intPart, "0")
+	p.minFrac = strings.Count(fracPart, "0")
+	p.maxFrac = len(fracPart)
+	if strings.Contains(strings.TrimLeft(fracPart, "0"), "0") {
+		return errors.New("required fraction digits must come before optional ones")
+	}
+	return nil
+}
+
+// splitSections splits a pattern on ';' outside of quoted literals
+func splitSections(pattern string) []string {
+	sections := []string{}
+	quoted := false
+	start := 0
+	for i, ch := range pattern {
+		switch {
+		case ch == '\'':
+			quoted = !quoted
+		case ch == ';' && !quoted:
+			sections = append(sections, pattern[start:i])
+			start = i + 1
+		}
+	}
+	return append(sections, pattern[start:])
+}
+
+// splitSection separates a pattern section into prefix, number part and suffix
+func splitSection(section string) (string, string, string, error) {
+	start, end := -1, -1
+	quoted := false
+	for i, ch := range section {
+		isDigit := !quoted && ch != '\'' && strings.ContainsRune("#0,.", ch)
+		if ch == '\'' {
+			quoted = !quoted
+		}
+		if !isDigit {
+			if start >= 0 && end < 0 {
+				end = i
+			}
+			continue
+		}
+		if end >= 0 {
+			return "", "", "", errors.New("digits must be contiguous")
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if quoted {
+		return "", "", "", errors.New("unterminated quote")
+	}
+	if start < 0 {
+		return section, "", "", nil
+	}
+	if end < 0 {
+		end = len(section)
+	}
+	return section[:start], section[start:end], section[end:], nil
+}
+
+// unquoteAffix resolves quoting in a prefix or suffix and returns the
+// multiplier implied by any percent or per-mille sign it contains
+func unquoteAffix(affix string) (string, int64) {
+	var sb strings.Builder
+	multiplier := int64(1)
+	quoted := false
+	runes := []rune(affix)
+	for i := 0; i < len(runes); i++ {
+		ch := runes[i]
+		switch {
+		case ch == '\'' && i+1 < len(runes) && runes[i+1] == '\'':
+			sb.WriteRune('\'')
+			i++
+		case ch == '\'':
+			quoted = !quoted
+		case !quoted && ch == '%':
+			multiplier = 100
+			sb.WriteRune(ch)
+		case !quoted && ch == '‰':
+			multiplier = 1000
+			sb.WriteRune(ch)
+		default:
+			sb.WriteRune(ch)
+		}
+	}
+	return sb.String(), multiplier
+}
+
+// FormatPattern formats a rational number with a pattern and locale
+func FormatPattern(result *big.Rat, pattern string, loc Locale) (string, error) {
+	p, err := ParsePattern(pattern)
+	if err != nil {
+		return "", err
+	}
+	return p.Format(result, loc), nil
+}
@@ -0,0 +1,87 @@
+package calculator
+
+import (
+	"context"
+	"math/big"
+	"time"
+)
+
+// Bindings supplies the values of a Program's variables by name
+type Bindings map[string]*big.Rat
+
+// Program is an expression tokenized and parsed once, for evaluating many
+// times with different variable values. A Program is immutable and safe for
+// concurrent use by multiple goroutines.
+type Program struct {
+	calc       *Calculator
+	expression *Expression
+	variables  []string
+}
+
+// Compile parses an expression that may name variables, such as
+// "price x qty + shipping", using the default Calculator. Variable names are
+// letters, digits and underscores starting with a letter or underscore. An
+// 'x' where an operator is expected is read as multiplication, so "2xrate" is
+// "2 x rate"; a lone 'x' where a number is expected is a variable.
+func Compile(expression string) (*Program, error) {
+	return defaultCalculator.Compile(expression)
+}
+
+// Compile parses an expression into a Program with the calculator's syntax
+// and limits, reporting errors as Calculate would
+func (c *Calculator) Compile(expression string) (*Program, error) {
+	compiler := *c
+	compiler.variables = true
+
+	expr, err := compiler.parseInput(expression)
+	if err != nil {
+		return nil, withSource(err, expression)
+	}
+
+	var variables []string
+	seen := map[string]bool{}
+	for _, token := range expr.Tokens {
+		if token.Type == VariableToken && !seen[token.Value] {
+			seen[token.Value] = true
+			variables = append(variables, token.Value)
+		}
+	}
+	return &Program{calc: c, expression: expr, variables: variables}, nil
+}
+
+// String returns the expression the program was compiled from
+func (p *Program) String() string {
+	return p.expression.Original
+}
+
+// Variables returns the names of the program's variables in order of first
+// use
+func (p *Program) Variables() []string {
+	return append([]string(nil), p.variables...)
+}
+
+// Eval evaluates the program with the given variable values. A variable
+// without a value gives an UnboundVariableError; extra bindings are ignored.
+func (p *Program) Eval(bindings Bindings) (*big.Rat, error) {
+	return p.EvalContext(context.Background(), bindings)
+}
+
+// EvalContext evaluates the program like Eval, stopping with a CanceledError
+// once ctx is done
+func (p *Program) EvalContext(ctx context.Context, bindings Bindings) (result *big.Rat, err error) {
+	if p.calc.observer != nil {
+		start := time.Now()
+		defer func() {
+			p.calc.evaluated(start, p.expression.Original, p.expression, ratBits(result), err)
+		}()
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, CanceledError{Err: ctxErr}
+	}
+
+	result, err = evaluate[*big.Rat](ctx, p.expression.PostfixTokens, p.calc.exactArithmetic(), p.calc.limits, bindings)
+	if err != nil {
+		return nil, withSource(err, p.expression.Original)
+	}
+	return result, nil
+}
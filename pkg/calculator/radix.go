@@ -0,0 +1,132 @@
+package calculator
+
+import (
+	"math/big"
+	"strings"
+)
+
+// DefaultMaxFractionDigits bounds how many fractional digits are expanded
+// before the output is truncated with "..."
+const DefaultMaxFractionDigits = 1000
+
+// radixDigits holds the digit characters for bases up to 36
+const radixDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// RadixOptions controls how a rational number is rendered in a given base
+type RadixOptions struct {
+	Base              int
+	Prefix            bool
+	Uppercase         bool
+	MaxFractionDigits int
+}
+
+// FormatRadix formats a rational number in the given base (2-36) with its conventional prefix
+func FormatRadix(result *big.Rat, base int) (string, error) {
+	return FormatRadixWithOptions(result, RadixOptions{Base: base, Prefix: true})
+}
+
+// FormatRadixWithOptions formats a rational number in any base from 2 to 36.
+// Non-integer results are expanded exactly: terminating fractions are written
+// out in full and repeating fractions enclose the repeating block in
+// parentheses, e.g. 1/3 in base 10 is "0.(3)".
+func FormatRadixWithOptions(result *big.Rat, opts RadixOptions) (string, error) {
+	if opts.Base < 2 || opts.Base > 36 {
+		return "", InvalidBaseError{Base: opts.Base}
+	}
+
+	maxDigits := opts.MaxFractionDigits
+	if maxDigits <= 0 {
+		maxDigits = DefaultMaxFractionDigits
+	}
+
+	// Split magnitude into integer part and remainder
+	num := new(big.Int).Abs(result.Num())
+	intPart, rem := new(big.Int).QuoRem(num, result.Denom(), new(big.Int))
+
+	digits := intPart.Text(opts.Base)
+	if rem.Sign() != 0 {
+		digits += "." + fractionDigits(rem, result.Denom(), opts.Base, maxDigits)
+	}
+	if opts.Uppercase {
+		digits = strings.ToUpper(digits)
+	}
+
+	var sb strings.Builder
+	if result.Sign() < 0 {
+		sb.WriteByte('-')
+	}
+	if opts.Prefix {
+		sb.WriteString(RadixPrefix(opts.Base))
+	}
+	sb.WriteString(digits)
+	return sb.String(), nil
+}
+
+// AutoBase chooses the output base for a result from the literals it was
+// computed from: if every number token was written in the same non-decimal
+// base and the result is an integer, that base is returned, otherwise 10
+func AutoBase(result *big.Rat, tokens []Token) int {
+	if !result.IsInt() {
+		return 10
+	}
+
+	base := 0
+	for _, token := range tokens {
+		if token.Type != NumberToken {
+			continue
+		}
+		numberType := literalType(token.Value)
+		if token.Number != nil {
+			numberType = token.Number.Type
+		}
+		if base != 0 && numberType.Base() != base {
+			return 10
+		}
+		base = numberType.Base()
+	}
+
+	if base == 0 {
+		return 10
+	}
+	return base
+}
+
+// RadixPrefix returns the literal prefix for a base, or "" if the base has none
+func RadixPrefix(base int) string {
+	switch base {
+	case 2:
+		return "0b"
+	case 8:
+		return "0o"
+	case 16:
+		return "0x"
+	}
+	return ""
+}
+
+// fractionDigits expands rem/den (0 < rem < den) in the given base using long
+// division, detecting a repeating block by remembering each remainder seen
+func fractionDigits(rem, den *big.Int, base, maxDigits int) string {
+	b := big.NewInt(int64(base))
+	r := new(big.Int).Set(rem)
+	d := new(big.Int)
+	seen := map[string]int{}
+	digits := []byte{}
+
+	for r.Sign() != 0 {
+		key := r.String()
+		if start, ok := seen[key]; ok {
+			return string(digits[:start]) + "(" + string(digits[start:]) + ")"
+		}
+		if len(digits) >= maxDigits {
+			return string(digits) + "..."
+		}
+		seen[key] = len(digits)
+
+		r.Mul(r, b)
+		d.QuoRem(r, den, r)
+		digits = append(digits, radixDigits[d.Int64()])
+	}
+
+	return string(digits)
+}
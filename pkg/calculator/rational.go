@@ -0,0 +1,330 @@
+package calculator
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Rational is an immutable exact rational number for use in application
+// structs, JSON and database rows. The zero value is 0. Arithmetic methods
+// return new values and never modify their operands, so Rationals can be
+// copied and shared freely.
+//
+// Rationals print as decimals when their expansion terminates, such as
+// "0.1", and as reduced fractions otherwise, such as "1/3". They marshal to
+// JSON as that string, so no precision is lost to JSON number handling, and
+// unmarshal from a JSON string or number.
+type Rational struct {
+	rat *big.Rat
+}
+
+// NewRational returns a Rational holding a copy of r
+func NewRational(r *big.Rat) Rational {
+	if r == nil {
+		return Rational{}
+	}
+	return Rational{rat: new(big.Rat).Set(r)}
+}
+
+// RationalFromInt returns the Rational n
+func RationalFromInt(n int64) Rational {
+	return Rational{rat: new(big.Rat).SetInt64(n)}
+}
+
+// RationalFromFrac returns the Rational num/den, or a DivisionByZeroError if
+// den is 0
+func RationalFromFrac(num, den int64) (Rational, error) {
+	if den == 0 {
+		return Rational{}, DivisionByZeroError{Position: -1}
+	}
+	return Rational{rat: big.NewRat(num, den)}, nil
+}
+
+// ParseRational parses any number literal Calculate accepts, such as "0.1",
+// "-0xFF" or "2 1/3", as well as fractions such as "1/3" and exponents such
+// as "1.5e-7"
+func ParseRational(s string) (Rational, error) {
+	s = strings.TrimSpace(s)
+	number, err := ParseNumber(s)
+	if err == nil {
+		return Rational{rat: number.Value}, nil
+	}
+	if r, ok := new(big.Rat).SetString(s); ok {
+		return Rational{rat: r}, nil
+	}
+	return Rational{}, err
+}
+
+// value returns the underlying rational, treating the zero Rational as 0.
+// The result must not be modified.
+func (x Rational) value() *big.Rat {
+	if x.rat == nil {
+		return new(big.Rat)
+	}
+	return x.rat
+}
+
+// Rat returns the value as a new big.Rat
+func (x Rational) Rat() *big.Rat {
+	return new(big.Rat).Set(x.value())
+}
+
+// Add returns x + y
+func (x Rational) Add(y Rational) Rational {
+	return Rational{rat: new(big.Rat).Add(x.value(), y.value())}
+}
+
+// Sub returns x - y
+func (x Rational) Sub(y Rational) Rational {
+	return Rational{rat: new(big.Rat).Sub(x.value(), y.value())}
+}
+
+// Mul returns x × y
+func (x Rational) Mul(y Rational) Rational {
+	return Rational{rat: new(big.Rat).Mul(x.value(), y.value())}
+}
+
+// Quo returns x / y, or a DivisionByZeroError if y is 0
+func (x Rational) Quo(y Rational) (Rational, error) {
+	if y.Sign() == 0 {
+		return Rational{}, DivisionByZeroError{Position: -1}
+	}
+	return Rational{rat: new(big.Rat).Quo(x.value(), y.value())}, nil
+}
+
+// Neg returns -x
+func (x Rational) Neg() Rational {
+	return Rational{rat: new(big.Rat).Neg(x.value())}
+}
+
+// Abs returns |x|
+func (x Rational) Abs() Rational {
+	return Rational{rat: new(big.Rat).Abs(x.value())}
+}
+
+// Cmp returns -1, 0 or +1 as x is less than, equal to or greater than y
+func (x Rational) Cmp(y Rational) int {
+	return x.value().Cmp(y.value())
+}
+
+// Equal reports whether x and y are the same number
+func (x Rational) Equal(y Rational) bool {
+	return x.Cmp(y) == 0
+}
+
+// Sign returns -1, 0 or +1 as x is negative, zero or positive
+func (x Rational) Sign() int {
+	return x.value().Sign()
+}
+
+// IsInt reports whether x is an integer
+func (x Rational) IsInt() bool {
+	return x.value().IsInt()
+}
+
+// String returns x as a decimal if its expansion terminates, such as "0.1",
+// and as a reduced fraction otherwise, such as "1/3"
+func (x Rational) String() string {
+	return formatExact(x.value())
+}
+
+// formatExact writes r as a decimal if its expansion terminates and as a
+// reduced fraction otherwise
+func formatExact(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	if scale, ok := terminatingScale(r); ok {
+		return r.FloatString(scale)
+	}
+	return r.String()
+}
+
+// Format implements fmt.Formatter. %v and %s print String(); %f prints a
+// decimal rounded to the precision, with ties away from zero, or as many
+// digits as a terminating decimal needs and 6 otherwise; %e and %E print
+// scientific notation with a two-digit exponent as fmt does for floats; %d
+// prints integers; %x, %X, %o and %b print base 16, 8 and 2 as
+// FormatRadixWithOptions does, with a prefix under the '#' flag. The '+'
+// flag, width, '-' and '0' flags are honored.
+func (x Rational) Format(f fmt.State, verb rune) {
+	r := x.value()
+	precision, hasPrecision := f.Precision()
+
+	var s string
+	switch verb {
+	case 'v', 's':
+		s = x.String()
+	case 'f', 'F':
+		switch {
+		case hasPrecision:
+			s = r.FloatString(precision)
+		default:
+			scale, ok := terminatingScale(r)
+			if !ok {
+				scale = 6
+			}
+			s = r.FloatString(scale)
+		}
+	case 'e', 'E':
+		if !hasPrecision {
+			precision = 6
+		}
+		s = formatE(r, precision, verb)
+	case 'd':
+		if !r.IsInt() {
+			fmt.Fprintf(f, "%%!d(calculator.Rational=%s)", x.String())
+			return
+		}
+		s = r.Num().String()
+	case 'x', 'X', 'o', 'b':
+		base := map[rune]int{'x': 16, 'X': 16, 'o': 8, 'b': 2}[verb]
+		formatted, err := FormatRadixWithOptions(r, RadixOptions{Base: base, Prefix: f.Flag('#'), Uppercase: verb == 'X'})
+		if err != nil {
+			fmt.Fprintf(f, "%%!%c(calculator.Rational=%s)", verb, x.String())
+			return
+		}
+		s = formatted
+	default:
+		fmt.Fprintf(f, "%%!%c(calculator.Rational=%s)", verb, x.String())
+		return
+	}
+
+	if f.Flag('+') && r.Sign() >= 0 {
+		s = "+" + s
+	}
+	if width, ok := f.Width(); ok && len([]rune(s)) < width {
+		padding := width - len([]rune(s))
+		switch {
+		case f.Flag('-'):
+			s += strings.Repeat(" ", padding)
+		case f.Flag('0'):
+			sign := ""
+			if s[0] == '-' || s[0] == '+' {
+				sign, s = s[:1], s[1:]
+			}
+			s = sign + strings.Repeat("0", padding) + s
+		default:
+			s = strings.Repeat(" ", padding) + s
+		}
+	}
+	fmt.Fprint(f, s)
+}
+
+// formatE writes r as %e does for floats: one digit before the point,
+// precision digits after it, and an exponent of at least two digits
+func formatE(r *big.Rat, precision int, verb rune) string {
+	digits, exponent := significantDigits(r, precision+1)
+
+	var sb strings.Builder
+	if r.Sign() < 0 {
+		sb.WriteByte('-')
+	}
+	sb.WriteString(digits[:1])
+	if precision > 0 {
+		sb.WriteByte('.')
+		sb.WriteString(digits[1:])
+	}
+	sb.WriteRune(verb)
+	if exponent < 0 {
+		sb.WriteByte('-')
+		exponent = -exponent
+	} else {
+		sb.WriteByte('+')
+	}
+	if exponent < 10 {
+		sb.WriteByte('0')
+	}
+	sb.WriteString(strconv.Itoa(exponent))
+	return sb.String()
+}
+
+// MarshalJSON implements json.Marshaler, writing x as a JSON string such as
+// "0.1" or "1/3"
+func (x Rational) MarshalJSON() ([]byte, error) {
+	return json.Marshal(x.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler, reading a JSON string in any
+// form ParseRational accepts, or a JSON number without going through float64.
+// A JSON null leaves x unchanged.
+func (x *Rational) UnmarshalJSON(data []byte) error {
+	text := strings.TrimSpace(string(data))
+	if text == "null" {
+		return nil
+	}
+	if strings.HasPrefix(text, `"`) {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+	}
+	parsed, err := ParseRational(text)
+	if err != nil {
+		return fmt.Errorf("calculator: cannot unmarshal %s into Rational: %w", data, err)
+	}
+	*x = parsed
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler, writing String()
+func (x Rational) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, reading any form
+// ParseRational accepts
+func (x *Rational) UnmarshalText(text []byte) error {
+	parsed, err := ParseRational(string(text))
+	if err != nil {
+		return err
+	}
+	*x = parsed
+	return nil
+}
+
+// Scan implements sql.Scanner for NUMERIC, DECIMAL, text and integer
+// columns. Floating-point columns are read from their shortest decimal form,
+// so a float64 0.1 scans as exactly 0.1. NULL is an error; use
+// sql.Null[Rational] for nullable columns.
+func (x *Rational) Scan(src any) error {
+	var text string
+	switch v := src.(type) {
+	case nil:
+		return errors.New("calculator: cannot scan NULL into Rational")
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	case int64:
+		*x = RationalFromInt(v)
+		return nil
+	case float64:
+		text = strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Errorf("calculator: cannot scan %T into Rational", src)
+	}
+
+	parsed, err := ParseRational(text)
+	if err != nil {
+		return err
+	}
+	*x = parsed
+	return nil
+}
+
+// Value implements driver.Valuer, storing the exact decimal as a string for
+// NUMERIC and DECIMAL columns. Values whose decimal expansion does not
+// terminate, such as 1/3, cannot be stored exactly and return an error; round
+// them first or store String() in a text column.
+func (x Rational) Value() (driver.Value, error) {
+	r := x.value()
+	if _, ok := terminatingScale(r); !ok {
+		return nil, fmt.Errorf("calculator: cannot store %s as a decimal: its expansion does not terminate", r.RatString())
+	}
+	return formatExact(r), nil
+}
@@ -0,0 +1,134 @@
+package calculator
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// RoundingMode selects how a value is rounded when digits are discarded
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds to nearest, ties away from zero
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds to nearest, ties to the even neighbour
+	RoundHalfEven
+	// RoundHalfDown rounds to nearest, ties towards zero
+	RoundHalfDown
+	// RoundUp rounds away from zero
+	RoundUp
+	// RoundDown rounds towards zero (truncation)
+	RoundDown
+	// RoundCeiling rounds towards positive infinity
+	RoundCeiling
+	// RoundFloor rounds towards negative infinity
+	RoundFloor
+	// RoundUnnecessary asserts that no rounding is needed
+	RoundUnnecessary
+)
+
+// roundingModeNames maps rounding modes to their names
+var roundingModeNames = []string{"half-up", "half-even", "half-down", "up", "down", "ceiling", "floor", "unnecessary"}
+
+// String returns the name of the rounding mode, e.g. "half-even"
+func (m RoundingMode) String() string {
+	if m < 0 || int(m) >= len(roundingModeNames) {
+		return fmt.Sprintf("RoundingMode(%d)", int(m))
+	}
+	return roundingModeNames[m]
+}
+
+// ParseRoundingMode returns the rounding mode with the given name
+func ParseRoundingMode(name string) (RoundingMode, error) {
+	for i, modeName := range roundingModeNames {
+		if strings.EqualFold(name, modeName) {
+			return RoundingMode(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown rounding mode %q: must be one of %s", name, strings.Join(roundingModeNames, ", "))
+}
+
+// roundRat rounds a rational to an integer using the given mode and reports
+// whether the result is exact. RoundUnnecessary truncates; callers check the
+// exactness flag to raise an error.
+func roundRat(x *big.Rat, mode RoundingMode) (*big.Int, bool) {
+	num := new(big.Int).Abs(x.Num())
+	den := x.Denom()
+
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		if x.Sign() < 0 {
+			q.Neg(q)
+		}
+		return q, true
+	}
+
+	// Compare the discarded fraction r/den with one half
+	half := new(big.Int).Lsh(r, 1).Cmp(den)
+	negative := x.Sign() < 0
+
+	var up bool
+	switch mode {
+	case RoundHalfUp:
+		up = half >= 0
+	case RoundHalfEven:
+		up = half > 0 || (half == 0 && q.Bit(0) == 1)
+	case RoundHalfDown:
+		up = half > 0
+	case RoundUp:
+		up = true
+	case RoundCeiling:
+		up = !negative
+	case RoundFloor:
+		up = negative
+	}
+
+	if up {
+		q.Add(q, big.NewInt(1))
+	}
+	if negative {
+		q.Neg(q)
+	}
+	return q, false
+}
+
+// roundHalfAwayFromZero rounds a rational to the nearest integer, with ties
+// rounded away from zero
+func roundHalfAwayFromZero(x *big.Rat) *big.Int {
+	q, _ := roundRat(x, RoundHalfUp)
+	return q
+}
+
+// pow10 returns 10^n as a rational; n may be negative
+func pow10(n int) *big.Rat {
+	p := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(n))), nil)
+	if n < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), p)
+	}
+	return new(big.Rat).SetInt(p)
+}
+
+// decimalExponent returns floor(log10(|x|)) for non-zero x, i.e. the power of
+// ten of its most significant decimal digit
+func decimalExponent(x *big.Rat) int {
+	a := new(big.Rat).Abs(x)
+
+	// Estimate from digit counts, then correct by at most one in either direction
+	e := len(a.Num().String()) - len(a.Denom().String())
+	if a.Cmp(pow10(e)) < 0 {
+		e--
+	}
+	if a.Cmp(pow10(e+1)) >= 0 {
+		e++
+	}
+	return e
+}
+
+// abs returns the absolute value of an int
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,156 @@
+package calculator
+
+import (
+	"context"
+	"math/big"
+	"strings"
+)
+
+// ScaledDecimal is an exact decimal value with a scale, the number of digits
+// kept after the decimal point, in the manner of Java's BigDecimal: 3.30 has
+// unscaled value 330 and scale 2, and is distinct from 3.3.
+type ScaledDecimal struct {
+	Unscaled *big.Int
+	Scale    int
+}
+
+// DivisionContext controls division in decimal mode when the quotient cannot
+// be represented exactly. The quotient is rounded to the larger of Scale and
+// the preferred scale of the division using Rounding; RoundUnnecessary makes
+// an inexact division an error. The zero value supplies no context, so an
+// inexact division is a RoundingRequiredError until Scale, Rounding or Set is
+// given.
+type DivisionContext struct {
+	Scale    int
+	Rounding RoundingMode
+	// Set supplies the context even when Scale and Rounding are zero, to
+	// round half-up to the preferred scale
+	Set bool
+}
+
+// supplied reports whether the context permits rounding an inexact quotient
+func (c DivisionContext) supplied() bool {
+	return c.Set || c.Scale != 0 || c.Rounding != RoundHalfUp
+}
+
+// NewScaledDecimal rounds a rational to the given scale
+func NewScaledDecimal(value *big.Rat, scale int, mode RoundingMode) (ScaledDecimal, bool) {
+	unscaled, exact := roundRat(new(big.Rat).Mul(value, pow10(scale)), mode)
+	return ScaledDecimal{Unscaled: unscaled, Scale: scale}, exact
+}
+
+// Rat returns the exact value as a rational
+func (d ScaledDecimal) Rat() *big.Rat {
+	return new(big.Rat).Mul(new(big.Rat).SetInt(d.Unscaled), pow10(-d.Scale))
+}
+
+// String formats the value with exactly Scale digits after the decimal point
+func (d ScaledDecimal) String() string {
+	return formatAtDigit(d.Rat(), -d.Scale)
+}
+
+// CalculateDecimal evaluates an expression in decimal mode, where every value
+// carries a scale taken from the digits written after the decimal point of its
+// literal, so that "1.10 + 2.20" is "3.30". Hexadecimal and binary literals
+// have scale 0. Scales combine as follows:
+//
+//   - addition and subtraction: the larger scale of the operands
+//   - multiplication: the sum of the operand scales
+//   - division: the preferred scale is the dividend's scale minus the
+//     divisor's, but not below zero; an exact quotient uses the smallest scale
+//     at least the preferred one that represents it, otherwise the quotient
+//     is rounded as described by ctx
+func CalculateDecimal(expression string, ctx DivisionContext) (ScaledDecimal, error) {
+	return defaultCalculator.CalculateDecimal(expression, ctx)
+}
+
+// CalculateDecimal evaluates an expression in decimal mode like the
+// package-level CalculateDecimal, with the calculator's limits and observer
+func (c *Calculator) CalculateDecimal(expression string, division DivisionContext) (ScaledDecimal, error) {
+	return c.CalculateDecimalContext(context.Background(), expression, division)
+}
+
+// CalculateDecimalContext evaluates an expression like CalculateDecimal,
+// stopping with a CanceledError once ctx is done
+func (c *Calculator) CalculateDecimalContext(ctx context.Context, expression string, division DivisionContext) (ScaledDecimal, error) {
+	return calculate[ScaledDecimal](ctx, c, expression, scaledArithmetic{ctx: division})
+}
+
+// literalScale returns the number of digits after the decimal point of a literal
+func literalScale(number *Number) int {
+	if number.Type != Decimal {
+		return 0
+	}
+	_, frac, _ := strings.Cut(number.Original, ".")
+	return len(strings.TrimSpace(frac))
+}
+
+// scaledArithmetic evaluates with scaled decimals
+type scaledArithmetic struct {
+	ctx DivisionContext
+}
+
+func (scaledArithmetic) number(token Token, number *Number) (ScaledDecimal, error) {
+	d, _ := NewScaledDecimal(number.Value, literalScale(number), RoundUnnecessary)
+	return d, nil
+}
+
+func (a scaledArithmetic) apply(left, right ScaledDecimal, token Token) (ScaledDecimal, error) {
+	value, err := performOperation(left.Rat(), right.Rat(), rune(token.Value[0]), token.Position)
+	if err != nil {
+		return ScaledDecimal{}, err
+	}
+
+	var scale int
+	switch token.Value {
+	case "+", "-":
+		scale = max(left.Scale, right.Scale)
+	case "x":
+		scale = left.Scale + right.Scale
+	case "/":
+		preferred := max(left.Scale-right.Scale, 0)
+		if exactScale, ok := terminatingScale(value); ok {
+			scale = max(preferred, exactScale)
+			break
+		}
+		if !a.ctx.supplied() || a.ctx.Rounding == RoundUnnecessary {
+			return ScaledDecimal{}, RoundingRequiredError{Position: token.Position}
+		}
+		d, _ := NewScaledDecimal(value, max(preferred, a.ctx.Scale), a.ctx.Rounding)
+		return d, nil
+	}
+
+	d, _ := NewScaledDecimal(value, scale, RoundUnnecessary)
+	return d, nil
+}
+
+func (scaledArithmetic) bits(value ScaledDecimal) int {
+	return value.Unscaled.BitLen()
+}
+
+// terminatingScale returns the number of decimal places needed to write a
+// rational exactly, or false if its decimal expansion does not terminate
+func terminatingScale(value *big.Rat) (int, bool) {
+	den := new(big.Int).Set(value.Denom())
+	twos, fives := 0, 0
+	two, five := big.NewInt(2), big.NewInt(5)
+	rem := new(big.Int)
+	for {
+		q, r := new(big.Int).QuoRem(den, two, rem)
+		if r.Sign() != 0 {
+			break
+		}
+		den, twos = q, twos+1
+	}
+	for {
+		q, r := new(big.Int).QuoRem(den, five, rem)
+		if r.Sign() != 0 {
+			break
+		}
+		den, fives = q, fives+1
+	}
+	if den.Cmp(big.NewInt(1)) != 0 {
+		return 0, false
+	}
+	return max(twos, fives), true
+}
@@ -0,0 +1,187 @@
+package calculator
+
+import (
+	"context"
+	"math/big"
+	"strings"
+)
+
+// SignificantValue is a result together with the precision it is known to.
+// Value is always the exact computed value; rounding happens only when the
+// value is formatted.
+type SignificantValue struct {
+	Value *big.Rat
+	// Figures is the number of significant figures, or 0 for exact values
+	Figures int
+	// LeastDigit is the decimal exponent of the least significant digit,
+	// e.g. -2 for a value known to the hundredths
+	LeastDigit int
+	// Exact is true when no measured literal contributed to the value, such
+	// as counts written in hexadecimal or binary
+	Exact bool
+}
+
+// String formats the value rounded to its least significant digit. Exact
+// values are written out in full.
+func (v SignificantValue) String() string {
+	if v.Exact {
+		s, _ := FormatRadixWithOptions(v.Value, RadixOptions{Base: 10})
+		return s
+	}
+	return formatAtDigit(v.Value, v.LeastDigit)
+}
+
+// FormatSignificant rounds a result to the given number of significant
+// figures and formats it positionally, e.g. 1234.5 to 3 figures is "1230"
+// and 0.012345 is "0.0123". Ties are rounded away from zero.
+func FormatSignificant(result *big.Rat, figures int) string {
+	if figures < 1 {
+		figures = 1
+	}
+	if result.Sign() == 0 {
+		return formatAtDigit(result, 1-figures)
+	}
+
+	// Round first so that a carry into a new digit moves the last digit too
+	_, exponent := significantDigits(result, figures)
+	return formatAtDigit(result, exponent-figures+1)
+}
+
+// CountSignificantFigures returns the significant figures of a number
+// literal and the decimal exponent of its least significant digit, following
+// the usual rules: leading zeros never count, trailing zeros count only after
+// a decimal point. Hexadecimal, binary and mixed-number literals are exact.
+func CountSignificantFigures(literal string) (figures int, leastDigit int, exact bool) {
+	if literalType(literal) != Decimal {
+		return 0, 0, true
+	}
+
+	digits := strings.TrimPrefix(strings.TrimSpace(literal), "-")
+	intPart, fracPart, hasPoint := strings.Cut(digits, ".")
+	all := strings.TrimLeft(intPart+fracPart, "0")
+
+	if hasPoint {
+		leastDigit = -len(fracPart)
+		figures = len(all)
+	} else {
+		trimmed := strings.TrimRight(all, "0")
+		leastDigit = len(all) - len(trimmed)
+		figures = len(trimmed)
+	}
+	if figures == 0 {
+		// A literal zero is known to its last written digit
+		figures = 1
+		if !hasPoint {
+			leastDigit = 0
+		}
+	}
+	return figures, leastDigit, false
+}
+
+// CalculateSignificant evaluates an expression tracking significant figures.
+// Each decimal literal's figures are read from its original text and
+// propagated with the standard rules: multiplication and division keep the
+// fewest significant figures of their operands, addition and subtraction keep
+// the least precise decimal place.
+func CalculateSignificant(expression string) (SignificantValue, error) {
+	return defaultCalculator.CalculateSignificant(expression)
+}
+
+// CalculateSignificant evaluates an expression tracking significant figures
+// like the package-level CalculateSignificant, with the calculator's limits
+// and observer
+func (c *Calculator) CalculateSignificant(expression string) (SignificantValue, error) {
+	return c.CalculateSignificantContext(context.Background(), expression)
+}
+
+// CalculateSignificantContext evaluates an expression like
+// CalculateSignificant, stopping with a CanceledError once ctx is done
+func (c *Calculator) CalculateSignificantContext(ctx context.Context, expression string) (SignificantValue, error) {
+	return calculate[SignificantValue](ctx, c, expression, sigFigArithmetic{})
+}
+
+// sigFigArithmetic evaluates exactly while tracking significant figures
+type sigFigArithmetic struct{}
+
+func (sigFigArithmetic) number(token Token, number *Number) (SignificantValue, error) {
+	figures, leastDigit, exact := CountSignificantFigures(number.Original)
+	return SignificantValue{
+		Value:      new(big.Rat).Set(number.Value),
+		Figures:    figures,
+		LeastDigit: leastDigit,
+		Exact:      exact,
+	}, nil
+}
+
+func (sigFigArithmetic) apply(left, right SignificantValue, token Token) (SignificantValue, error) {
+	value, err := performOperation(left.Value, right.Value, rune(token.Value[0]), token.Position)
+	if err != nil {
+		return SignificantValue{}, err
+	}
+
+	result := SignificantValue{Value: value}
+	switch {
+	case left.Exact && right.Exact:
+		result.Exact = true
+		return result, nil
+	case left.Exact:
+		right, left = left, right
+	}
+
+	// left is now measured; right may be exact and then imposes no limit
+	exponent := 0
+	if value.Sign() != 0 {
+		exponent = decimalExponent(value)
+	}
+
+	switch token.Value {
+	case "+", "-":
+		result.LeastDigit = left.LeastDigit
+		if !right.Exact && right.LeastDigit > result.LeastDigit {
+			result.LeastDigit = right.LeastDigit
+		}
+		result.Figures = exponent - result.LeastDigit + 1
+		if value.Sign() == 0 || result.Figures < 0 {
+			result.Figures = 0
+		}
+	default:
+		result.Figures = left.Figures
+		if !right.Exact && right.Figures < result.Figures {
+			result.Figures = right.Figures
+		}
+		result.LeastDigit = exponent - result.Figures + 1
+	}
+
+	return result, nil
+}
+
+func (sigFigArithmetic) bits(value SignificantValue) int {
+	return ratBits(value.Value)
+}
+
+// formatAtDigit rounds a value to a multiple of 10^leastDigit and formats it
+// positionally, keeping trailing zeros down to that digit
+func formatAtDigit(value *big.Rat, leastDigit int) string {
+	scaled := new(big.Rat).Mul(value, pow10(-leastDigit))
+	n := roundHalfAwayFromZero(scaled)
+
+	negative := n.Sign() < 0
+	digits := new(big.Int).Abs(n).String()
+
+	if leastDigit >= 0 {
+		if digits != "0" {
+			digits += strings.Repeat("0", leastDigit)
+		}
+	} else {
+		places := -leastDigit
+		if len(digits) <= places {
+			digits = strings.Repeat("0", places-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-places] + "." + digits[len(digits)-places:]
+	}
+
+	if negative {
+		return "-" + digits
+	}
+	return digits
+}
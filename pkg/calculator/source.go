@@ -0,0 +1,147 @@
+package calculator
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SourceError is implemented by errors that refer to a span of the expression.
+// The Calculate functions fill in the snippet; errors from Tokenize and
+// ParseExpression carry only the span.
+type SourceError interface {
+	error
+	// Span returns the rune offsets of the start and end (exclusive) of the
+	// offending text
+	Span() (start, end int)
+	// Snippet returns the expression with the offending text underlined, or
+	// "" when the expression is not known
+	Snippet() string
+	// Where returns the location of the start of the offending text
+	Where() Location
+}
+
+// FormatSnippet renders an expression with a caret under the rune at start
+// and tildes under the rest of the span up to end, e.g.
+//
+//	0.1 + + 0.2
+//	      ^
+//
+// For multi-line expressions only the line holding start is shown, behind a
+// gutter with its line number, and the underline stops at the end of that line:
+//
+//	2 | 3 x + 4
+//	  |     ^
+func FormatSnippet(expression string, start, end int) string {
+	runes := []rune(expression)
+	start = min(max(start, 0), len(runes))
+	end = max(end, start+1)
+
+	loc := newLocator(expression).locate(start)
+	lineStart := start - loc.Column + 1
+	lineEnd := lineStart
+	for lineEnd < len(runes) && runes[lineEnd] != '\n' {
+		lineEnd++
+	}
+	line := runes[lineStart:lineEnd]
+	end = min(end, max(lineEnd, start+1))
+
+	var sb strings.Builder
+	gutter := ""
+	if strings.Contains(expression, "\n") {
+		number := strconv.Itoa(loc.Line)
+		sb.WriteString(number + " | ")
+		gutter = strings.Repeat(" ", len(number)) + " | "
+	}
+	sb.WriteString(string(line))
+	sb.WriteByte('\n')
+	sb.WriteString(gutter)
+
+	// Keep tabs in the padding so the caret lines up however tabs are shown
+	for _, r := range line[:start-lineStart] {
+		if r == '\t' {
+			sb.WriteRune('\t')
+		} else {
+			sb.WriteByte(' ')
+		}
+	}
+	sb.WriteByte('^')
+	sb.WriteString(strings.Repeat("~", end-start-1))
+	return sb.String()
+}
+
+// calculate parses an expression and evaluates it with the given arithmetic,
+// attaching the source snippet to any error and reporting the evaluation to
+// the observer
+func calculate[T any](ctx context.Context, c *Calculator, expression string, arith arithmetic[T]) (T, error) {
+	_, result, err := calculateExpression(ctx, c, expression, arith)
+	return result, err
+}
+
+// calculateExpression evaluates an expression like calculate, also returning
+// the parsed expression, which is nil if parsing failed
+func calculateExpression[T any](ctx context.Context, c *Calculator, expression string, arith arithmetic[T]) (expr *Expression, result T, err error) {
+	if c.observer != nil {
+		start := time.Now()
+		defer func() {
+			bits := 0
+			if err == nil {
+				bits = arith.bits(result)
+			}
+			c.evaluated(start, expression, expr, bits, err)
+		}()
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, result, CanceledError{Err: ctxErr}
+	}
+	expr, err = c.parseInput(expression)
+	if err != nil {
+		return nil, result, withSource(err, expression)
+	}
+
+	result, err = evaluate[T](ctx, expr.PostfixTokens, arith, c.limits, nil)
+	if err != nil {
+		return expr, result, withSource(err, expression)
+	}
+	return expr, result, nil
+}
+
+// sourced is implemented by the errors that refer to a span of the
+// expression
+type sourced interface {
+	withSource(expression string) error
+}
+
+// withSource fills in the location, span end and snippet of an error from
+// the expression
+func withSource(err error, expression string) error {
+	if e, ok := err.(sourced); ok {
+		return e.withSource(expression)
+	}
+	return err
+}
+
+// annotate returns the location, end and snippet of a span, or nothing new
+// when the position is unknown
+func annotate(expression string, position, end int) (Location, int, string) {
+	if position < 0 {
+		return Location{}, end, ""
+	}
+	end = spanEnd(position, end)
+	return Locate(expression, position), end, FormatSnippet(expression, position, end)
+}
+
+// spanEnd returns the end of a span, defaulting to the single rune at position
+func spanEnd(position, end int) int {
+	if position >= 0 && end <= position {
+		return position + 1
+	}
+	return end
+}
+
+// tokenEnd returns the rune offset just past a token
+func tokenEnd(token Token) int {
+	return token.Position + len([]rune(token.Value))
+}
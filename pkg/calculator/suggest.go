@@ -0,0 +1,162 @@
+package calculator
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// SuggestionOf returns the fix suggested by the first error in err's tree
+// that has one, or ""
+func SuggestionOf(err error) string {
+	var invalid InvalidCharacterError
+	if errors.As(err, &invalid) && invalid.Suggestion != "" {
+		return invalid.Suggestion
+	}
+	var parseErr ParseError
+	if errors.As(err, &parseErr) {
+		return parseErr.Suggestion
+	}
+	return ""
+}
+
+// invalidCharacter builds the error for the invalid character at runes[i],
+// with a suggestion for fixing common mistakes
+func invalidCharacter(runes []rune, i int, locs locator) InvalidCharacterError {
+	return InvalidCharacterError{
+		Character:  runes[i],
+		Position:   i,
+		Location:   locs.locate(i),
+		Suggestion: suggestCharacter(runes, i),
+	}
+}
+
+// suggestCharacter returns a hint for the invalid character at runes[i], such
+// as "use 'x' for multiplication", or "" when there is nothing useful to say
+func suggestCharacter(runes []rune, i int) string {
+	ch := runes[i]
+	prev, next := runeAt(runes, i-1), runeAt(runes, i+1)
+
+	switch ch {
+	case '*', '×', '·', '⋅', '∗':
+		return "use 'x' for multiplication"
+	case 'X':
+		return "use lowercase 'x' for multiplication"
+	case '÷', ':':
+		return "use '/' for division"
+	case '−', '–', '—':
+		return "use '-' for subtraction and negative numbers"
+	case '＋':
+		return "use '+' for addition"
+	case '(', ')', '[', ']', '{', '}':
+		return "parentheses are not supported; rely on precedence, where 'x' and '/' bind tighter than '+' and '-'"
+	case '^':
+		return "exponentiation is not supported; write repeated multiplication such as 2 x 2 x 2"
+	case ',':
+		if isDigit(prev) && isDigit(next) {
+			return suggestComma(runes, i)
+		}
+	case 'O', 'o':
+		if prev == '0' && !isDigit(runeAt(runes, i-2)) {
+			return "octal literals are not supported; use a 0x or 0b prefix"
+		}
+		if next == 'x' || next == 'X' || next == 'b' || next == 'B' {
+			return "use the digit 0, not the letter " + string(ch) + ", in the 0" + string(next) + " prefix"
+		}
+	case 'h', 'H':
+		if isHexDigit(prev) && !strings.Contains(strings.ToLower(wordBefore(runes, i)), "x") {
+			return "write hexadecimal numbers with a 0x prefix, e.g. 0x" + wordBefore(runes, i)
+		}
+	}
+
+	// Hexadecimal digits without a prefix, such as "FF", and scientific
+	// notation such as "1e5" are read as invalid letters
+	if isHexDigit(ch) && !isDigit(ch) {
+		if (ch == 'e' || ch == 'E') && isDigit(prev) && (isDigit(next) || next == '-' || next == '+') {
+			return "scientific notation is not supported; write the number out in full"
+		}
+		if word := wordBefore(runes, i); !strings.ContainsAny(word, "xXbB") {
+			return "write hexadecimal numbers with a 0x prefix, e.g. 0x" + word + hexRunFrom(runes, i)
+		}
+	}
+
+	// A letter straight after a hexadecimal literal, as in "0x1G"
+	if unicode.IsLetter(ch) {
+		word := strings.ToLower(strings.TrimPrefix(wordBefore(runes, i), "-"))
+		if strings.HasPrefix(word, "0x") {
+			return "'" + string(ch) + "' is not a hexadecimal digit; use 0-9 and A-F"
+		}
+	}
+	return ""
+}
+
+// suggestComma explains a comma between digits, which is either a decimal
+// comma or a thousands separator
+func suggestComma(runes []rune, i int) string {
+	start := i
+	for start > 0 && (isDigit(runes[start-1]) || runes[start-1] == ',') {
+		start--
+	}
+	end := i
+	for end < len(runes) && (isDigit(runes[end]) || runes[end] == ',') {
+		end++
+	}
+	number := string(runes[start:end])
+	groups := strings.Split(number, ",")
+
+	thousands := len(groups[0]) <= 3
+	for _, group := range groups[1:] {
+		thousands = thousands && len(group) == 3
+	}
+	if thousands {
+		decimal := strings.Join(groups[:len(groups)-1], "") + "." + groups[len(groups)-1]
+		if len(groups) > 2 {
+			return "remove the thousands separators: " + strings.Join(groups, "")
+		}
+		return "remove the thousands separator (" + strings.Join(groups, "") + ") or use '.' as the decimal separator (" + decimal + ")"
+	}
+	if len(groups) == 2 {
+		return "use '.' as the decimal separator: " + groups[0] + "." + groups[1]
+	}
+	return "use '.' as the decimal separator"
+}
+
+// suggestLiteral returns a hint for a malformed number literal, or ""
+func suggestLiteral(value string) string {
+	digits := strings.ToLower(strings.TrimPrefix(value, "-"))
+	switch {
+	case digits == "0x":
+		return "add hexadecimal digits after 0x, e.g. 0x1F"
+	case digits == "0b":
+		return "add binary digits after 0b, e.g. 0b101"
+	case strings.Trim(digits, ".") == "":
+		return "write a digit before or after the decimal point, e.g. 0.5"
+	}
+	return ""
+}
+
+// runeAt returns runes[i], or 0 when i is out of range
+func runeAt(runes []rune, i int) rune {
+	if i < 0 || i >= len(runes) {
+		return 0
+	}
+	return runes[i]
+}
+
+// hexRunFrom returns the run of hexadecimal digits starting at i
+func hexRunFrom(runes []rune, i int) string {
+	end := i
+	for end < len(runes) && isHexDigit(runes[end]) {
+		end++
+	}
+	return string(runes[i:end])
+}
+
+// wordBefore returns the run of letters and digits ending just before i
+func wordBefore(runes []rune, i int) string {
+	start := i
+	for start > 0 && (unicode.IsLetter(runes[start-1]) || unicode.IsDigit(runes[start-1])) {
+		start--
+	}
+	return string(runes[start:i])
+}
@@ -6,28 +6,77 @@ import (
 	"unicode"
 )
 
-// ValidCharacterSet defines allowed characters for input validation
+// ValidCharacterSet defines allowed characters for input validation.
+//
+// Deprecated: the tokenizer no longer consults ValidCharacterSet, and
+// changing it has no effect; the characters accepted follow the operator
+// table of the Calculator in use.
 var ValidCharacterSet = regexp.MustCompile(`^[A-Fa-f0-9x+\-\s\t\n/.]*$`)
 
 // Tokenize converts input string into sequence of tokens
 func Tokenize(expression string) ([]Token, error) {
+	return TokenizeWithOptions(expression, Options{})
+}
+
+// TokenizeWithOptions converts input string into sequence of tokens, accepting
+// the optional syntax enabled in opts
+func TokenizeWithOptions(expression string, opts Options) ([]Token, error) {
+	return defaultCalculator.withOptions(opts).tokenize(expression)
+}
+
+// tokenize converts input string into sequence of tokens, stopping at the
+// first error
+func (c *Calculator) tokenize(expression string) ([]Token, error) {
 	if strings.TrimSpace(expression) == "" {
 		return nil, EmptyExpressionError{}
 	}
 
-	// Validate character set
-	if !ValidCharacterSet.MatchString(expression) {
-		// Find first invalid character
-		for i, ch := range expression {
-			if !isValidCharacter(ch) {
-				return nil, InvalidCharacterError{Character: ch, Position: i}
-			}
+	// Report invalid characters before malformed literals, with rune
+	// positions like the main loop. Quoted function arguments may hold any
+	// character.
+	runes := []rune(expression)
+	calls := len(c.functions) > 0
+	quoted := false
+	for i, ch := range runes {
+		if calls && ch == '"' {
+			quoted = !quoted
+		}
+		if quoted || isValidCharacter(ch) || c.operators.spells(ch) {
+			continue
+		}
+		if (c.variables || calls) && isNameRune(ch) || calls && strings.ContainsRune(`(),"`, ch) {
+			continue
 		}
+		return nil, invalidCharacter(runes, i, newLocator(expression))
 	}
 
+	var first error
+	tokens := c.scanTokens(expression, func(err error) bool {
+		first = err
+		return false
+	})
+	if first != nil {
+		// Unterminated text leaves its call unclosed, which is reported
+		// first as the parser and ValidateAll do; tokens is nil after any
+		// other error
+		if errs := checkCalls(tokens); len(errs) > 0 {
+			return nil, errs[0]
+		}
+		return nil, first
+	}
+	return tokens, nil
+}
+
+// scanTokens splits an expression into tokens, passing each invalid character
+// and malformed literal to report. Scanning stops when report returns false;
+// otherwise invalid characters are skipped and malformed literals are kept as
+// number tokens without a parsed Number, so that the scan can continue.
+func (c *Calculator) scanTokens(expression string, report func(error) bool) []Token {
+	runes := []rune(expression)
+	locs := newLocator(expression)
 	tokens := []Token{}
+	depth := 0
 	i := 0
-	runes := []rune(expression)
 
 	for i < len(runes) {
 		ch := runes[i]
@@ -44,31 +93,143 @@ func Tokenize(expression string) ([]Token, error) {
 			(ch == '-' && isStartOfNumber(runes, i, tokens)) {
 			start := i
 			value, newPos := parseNumberToken(runes, i)
+			if c.literals.has(Mixed) {
+				if mixed, end, ok := scanMixedNumber(runes, i); ok {
+					value, newPos = mixed, end
+				}
+			}
+			number, err := ParseNumber(value)
+			if err != nil {
+				err = ParseError{
+					Message:    "Invalid number format: " + value,
+					Position:   start,
+					End:        newPos,
+					Location:   locs.locate(start),
+					Err:        err,
+					Suggestion: suggestLiteral(value),
+				}
+			} else if !c.literals.has(number.Type) {
+				err = ParseError{
+					Message:  capitalize(literalName(number.Type)) + " literals are not allowed: " + value,
+					Position: start,
+					End:      newPos,
+					Location: locs.locate(start),
+				}
+				number = nil
+			}
+			if err != nil {
+				if !report(err) {
+					return nil
+				}
+			}
 			tokens = append(tokens, Token{
 				Type:     NumberToken,
 				Value:    value,
 				Position: start,
+				Location: locs.locate(start),
+				Number:   number,
 			})
 			i = newPos
 			continue
 		}
 
-		// Handle operators AFTER checking for negative numbers
-		if isOperator(ch) {
+		// Function calls: a name followed by an opening parenthesis. Where an
+		// operator is expected the call is still read, unless an operator is
+		// spelled there, so that the parser reports the missing operator.
+		if len(c.functions) > 0 && isNameStart(ch) && !(expectOperator(tokens) && c.operators.spellsAt(runes, i)) {
+			end := i
+			for end < len(runes) && isNameRune(runes[end]) {
+				end++
+			}
+			open := end
+			for open < len(runes) && (runes[open] == ' ' || runes[open] == '\t') {
+				open++
+			}
+			if open < len(runes) && runes[open] == '(' {
+				name := string(runes[i:end])
+				tokens = append(tokens,
+					Token{Type: FunctionToken, Value: name, Position: i, Location: locs.locate(i), Function: c.functions[name]},
+					Token{Type: LeftParenToken, Value: "(", Position: open, Location: locs.locate(open)})
+				depth++
+				i = open + 1
+				continue
+			}
+		}
+
+		// Argument separators, closing parentheses and quoted text, which
+		// only appear inside calls
+		if depth > 0 && (ch == ',' || ch == ')') {
+			tokenType := CommaToken
+			if ch == ')' {
+				tokenType = RightParenToken
+				depth--
+			}
+			tokens = append(tokens, Token{Type: tokenType, Value: string(ch), Position: i, Location: locs.locate(i)})
+			i++
+			continue
+		}
+		if depth > 0 && ch == '"' {
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				// The text runs to the end, so the tokens so far are kept
+				// even when scanning stops here
+				report(ParseError{Message: "Unterminated text", Position: i, End: end, Location: locs.locate(i)})
+				i = end
+				continue
+			}
+			tokens = append(tokens, Token{Type: TextToken, Value: string(runes[i : end+1]), Position: i, Location: locs.locate(i)})
+			i = end + 1
+			continue
+		}
+
+		// Handle operators AFTER checking for negative numbers, trying the
+		// longest spelling first. In a Program a name where a number is
+		// expected is a variable even if it starts like a binary operator, so
+		// "x x x" multiplies the variable x by itself.
+		op, end, matched := c.operators.match(runes, i)
+		if matched && c.variables && isNameStart(ch) && !op.unary() && !expectOperator(tokens) {
+			matched = false
+		}
+		if matched {
 			tokens = append(tokens, Token{
 				Type:     OperatorToken,
-				Value:    string(ch),
+				Value:    string(runes[i:end]),
 				Position: i,
+				Location: locs.locate(i),
+				Operator: op,
 			})
-			i++
+			i = end
+			continue
+		}
+
+		// Variable names, where an 'x' that is expected to be an operator has
+		// already been read as multiplication, as in "2x3" or "2xrate"
+		if c.variables && isNameStart(ch) {
+			end := i
+			for end < len(runes) && isNameRune(runes[end]) {
+				end++
+			}
+			tokens = append(tokens, Token{
+				Type:     VariableToken,
+				Value:    string(runes[i:end]),
+				Position: i,
+				Location: locs.locate(i),
+			})
+			i = end
 			continue
 		}
 
 		// If we get here, it's an invalid character
-		return nil, InvalidCharacterError{Character: ch, Position: i}
+		if !report(invalidCharacter(runes, i, locs)) {
+			return nil
+		}
+		i++
 	}
 
-	return tokens, nil
+	return tokens
 }
 
 // isValidCharacter checks if character is in allowed set
@@ -82,10 +243,24 @@ func isValidCharacter(ch rune) bool {
 		unicode.IsSpace(ch)
 }
 
-// isOperator checks if character is a mathematical operator
-func isOperator(ch rune) bool {
-	_, exists := OperatorMap[ch]
-	return exists
+// isNameStart checks if character can begin a variable name
+func isNameStart(ch rune) bool {
+	return unicode.IsLetter(ch) || ch == '_'
+}
+
+// isNameRune checks if character can continue a variable name
+func isNameRune(ch rune) bool {
+	return isNameStart(ch) || isDigit(ch)
+}
+
+// expectOperator reports whether the next token should be an operator,
+// following a value or a function call
+func expectOperator(tokens []Token) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+	last := tokens[len(tokens)-1].Type
+	return last.IsOperand() || last == RightParenToken
 }
 
 // isDigit checks if character is a digit
@@ -114,12 +289,12 @@ func isStartOfNumber(runes []rune, i int, tokens []Token) bool {
 	next := runes[i+1]
 	if isDigit(next) || next == '.' {
 		// This is negative decimal number if we're at start or after operator
-		return len(tokens) == 0 || tokens[len(tokens)-1].Type == OperatorToken
+		return !expectOperator(tokens)
 	}
 
 	// Check for negative hex number: -0x
 	if next == '0' && i+2 < len(runes) && (runes[i+2] == 'x' || runes[i+2] == 'X') {
-		return len(tokens) == 0 || tokens[len(tokens)-1].Type == OperatorToken
+		return !expectOperator(tokens)
 	}
 
 	return false
@@ -144,6 +319,15 @@ func parseNumberToken(runes []rune, i int) (string, int) {
 		return string(runes[start:i]), i
 	}
 
+	// Check for binary number
+	if i+1 < len(runes) && runes[i] == '0' && (runes[i+1] == 'b' || runes[i+1] == 'B') {
+		i += 2 // Skip 0b
+		for i < len(runes) && (runes[i] == '0' || runes[i] == '1') {
+			i++
+		}
+		return string(runes[start:i]), i
+	}
+
 	// Parse decimal number
 	// Integer part
 	for i < len(runes) && isDigit(runes[i]) {
@@ -160,3 +344,50 @@ func parseNumberToken(runes []rune, i int) (string, int) {
 
 	return string(runes[start:i]), i
 }
+
+// scanMixedNumber recognises a mixed number literal such as "2 1/3" starting
+// at position i: a whole number, spaces or tabs, and a proper fraction written
+// without spaces around the slash
+func scanMixedNumber(runes []rune, i int) (string, int, bool) {
+	start := i
+	if i < len(runes) && runes[i] == '-' {
+		i++
+	}
+
+	wholeEnd := skipDigits(runes, i)
+	if wholeEnd == i {
+		return "", i, false
+	}
+
+	fracStart := wholeEnd
+	for fracStart < len(runes) && (runes[fracStart] == ' ' || runes[fracStart] == '\t') {
+		fracStart++
+	}
+	if fracStart == wholeEnd {
+		return "", i, false
+	}
+
+	numEnd := skipDigits(runes, fracStart)
+	if numEnd == fracStart || numEnd >= len(runes) || runes[numEnd] != '/' {
+		return "", i, false
+	}
+	denEnd := skipDigits(runes, numEnd+1)
+	if denEnd == numEnd+1 || (denEnd < len(runes) && runes[denEnd] == '.') {
+		return "", i, false
+	}
+
+	// Improper fractions and zero denominators are left to be read as division
+	value := string(runes[start:denEnd])
+	if _, err := ParseMixedNumber(value); err != nil {
+		return "", i, false
+	}
+	return value, denEnd, true
+}
+
+// skipDigits returns the position after a run of decimal digits starting at i
+func skipDigits(runes []rune, i int) int {
+	for i < len(runes) && isDigit(runes[i]) {
+		i++
+	}
+	return i
+}
@@ -8,8 +8,21 @@ type NumberType int
 const (
 	Decimal NumberType = iota
 	Hexadecimal
+	Binary
+	Mixed
 )
 
+// Base returns the radix a number type is written in
+func (t NumberType) Base() int {
+	switch t {
+	case Hexadecimal:
+		return 16
+	case Binary:
+		return 2
+	}
+	return 10
+}
+
 // TokenType represents the type of a parsed token
 type TokenType int
 
@@ -17,8 +30,50 @@ const (
 	NumberToken TokenType = iota
 	OperatorToken
 	WhitespaceToken
+	// VariableToken is a name bound to a value when a Program is evaluated
+	VariableToken
+	// FunctionToken names the function in a call such as "tax(amount)"
+	FunctionToken
+	// LeftParenToken, RightParenToken and CommaToken delimit the arguments
+	// of a function call
+	LeftParenToken
+	RightParenToken
+	CommaToken
+	// TextToken is a quoted function argument such as "EUR"; its Value keeps
+	// the quotes
+	TextToken
 )
 
+// String returns the name of the token type, such as "number"
+func (t TokenType) String() string {
+	switch t {
+	case NumberToken:
+		return "number"
+	case OperatorToken:
+		return "operator"
+	case WhitespaceToken:
+		return "whitespace"
+	case VariableToken:
+		return "variable"
+	case FunctionToken:
+		return "function"
+	case LeftParenToken:
+		return "left_paren"
+	case RightParenToken:
+		return "right_paren"
+	case CommaToken:
+		return "comma"
+	case TextToken:
+		return "text"
+	}
+	return "unknown"
+}
+
+// IsOperand reports whether tokens of this type stand for a value
+func (t TokenType) IsOperand() bool {
+	return t == NumberToken || t == VariableToken || t == TextToken
+}
+
 // Associativity represents operator associativity
 type Associativity int
 
@@ -27,6 +82,16 @@ const (
 	Right
 )
 
+// Options controls optional syntax accepted when tokenizing and calculating
+type Options struct {
+	// MixedNumbers reads a whole number followed by a proper fraction, such as
+	// "2 1/3", as a single literal
+	MixedNumbers bool
+	// Limits bounds the work done on an expression; the zero value imposes
+	// no limits
+	Limits Limits
+}
+
 // Number represents a parsed numeric value
 type Number struct {
 	Value    *big.Rat
@@ -34,26 +99,64 @@ type Number struct {
 	Type     NumberType
 }
 
-// Operator represents a mathematical operation
+// Operator represents a mathematical operation. Custom operators are
+// registered on a Calculator with WithOperator; the built-in '+', '-', 'x' and
+// '/' need no Apply.
 type Operator struct {
-	Symbol        rune
+	Symbol rune
+	// Name spells the operator as a word, such as "%of", instead of Symbol
+	Name          string
 	Precedence    int
 	Associativity Associativity
+	// Arity is 2 for operators written between their operands and 1 for
+	// prefix operators written before their operand; 0 means 2
+	Arity int
+	// Apply computes the operator's result from its operands, left first
+	Apply func(operands ...*big.Rat) (*big.Rat, error)
+}
+
+// String returns how the operator is written in expressions
+func (o Operator) String() string {
+	if o.Name != "" {
+		return o.Name
+	}
+	return string(o.Symbol)
+}
+
+// unary reports whether the operator is a prefix operator
+func (o Operator) unary() bool {
+	return o.Arity == 1
 }
 
-// Token represents a parsed element from input
+// Token represents a parsed element from input. Position is the rune offset
+// of the token and Location places it by byte offset, line and column. Number
+// tokens produced by Tokenize carry their parsed value in Number; it is nil
+// for operators and for tokens built by hand, which are parsed from Value when
+// evaluated. Operator tokens produced by Tokenize carry their definition in
+// Operator; tokens built by hand leave it nil and name a built-in operator in
+// Value. Function tokens carry their definition in Function, or nil for an
+// unknown name, and in postfix order record the number of arguments in Args.
 type Token struct {
 	Type     TokenType
 	Value    string
 	Position int
+	Location Location
+	Number   *Number
+	Operator *Operator
+	Function *Function
+	Args     int
 }
 
-// Expression represents a complete mathematical expression
+// Expression represents a complete mathematical expression. Result and
+// Numbers are filled in by CalculateExpression.
 type Expression struct {
 	Original      string
 	Tokens        []Token
 	PostfixTokens []Token
 	Result        *big.Rat
+	// Numbers are the number literals in the order written, each with its
+	// original text and NumberType
+	Numbers []Number
 }
 
 // Predefined operators with precedence
@@ -64,7 +167,11 @@ var (
 	DivisionOp       = Operator{Symbol: '/', Precedence: 2, Associativity: Left}
 )
 
-// OperatorMap maps operator symbols to their definitions
+// OperatorMap maps operator symbols to their definitions.
+//
+// Deprecated: the package-level functions no longer consult OperatorMap, and
+// changing it has no effect; use New with WithOperators to build a Calculator
+// with a different operator table.
 var OperatorMap = map[rune]Operator{
 	'+': AdditionOp,
 	'-': SubtractionOp,
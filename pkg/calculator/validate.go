@@ -0,0 +1,130 @@
+package calculator
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// ValidateAll checks an expression without evaluating it, reporting every
+// invalid character, malformed literal and structural error instead of
+// stopping at the first. It returns nil for a well-formed expression and
+// otherwise an ErrorList ordered by position, whose errors carry snippets as
+// they do from Calculate. Errors that only arise during evaluation, such as
+// division by zero, are not detected.
+//
+// Structural errors next to an invalid character are not reported, since the
+// character may be a mistyped operator or digit: "5 * 3" yields one error for
+// the '*' rather than a second one for the missing operator.
+func ValidateAll(expression string, opts Options) error {
+	return defaultCalculator.withOptions(opts).ValidateAll(expression)
+}
+
+// ValidateAll checks an expression like the package-level ValidateAll, with
+// the calculator's syntax and limits
+func (c *Calculator) ValidateAll(expression string) error {
+	if strings.TrimSpace(expression) == "" {
+		return ErrorList{EmptyExpressionError{}}
+	}
+	if err := c.limits.checkLength(expression); err != nil {
+		return ErrorList{err}
+	}
+
+	var errs ErrorList
+	var gaps []int
+	tokens := c.scanTokens(expression, func(err error) bool {
+		if invalid, ok := err.(InvalidCharacterError); ok {
+			gaps = append(gaps, invalid.Position)
+		}
+		errs = append(errs, err)
+		return true
+	})
+	errs = append(errs, c.checkStructure(tokens, gaps)...)
+	errs = append(errs, checkCalls(tokens)...)
+	if err := c.limits.checkTokens(tokens); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	for i, err := range errs {
+		errs[i] = withSource(err, expression)
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errorPosition(errs[i]) < errorPosition(errs[j])
+	})
+	return errs
+}
+
+// checkStructure reports every place where tokens break the alternation of
+// numbers and operators, allowing prefix operators before a number and
+// function calls in its place. Positions in gaps hold skipped invalid
+// characters, after which either kind of token is accepted.
+func (c *Calculator) checkStructure(tokens []Token, gaps []int) ErrorList {
+	var errs ErrorList
+
+	// hasGap reports whether an invalid character lies in [from, to)
+	hasGap := func(from, to int) bool {
+		for _, gap := range gaps {
+			if gap >= from && gap < to {
+				return true
+			}
+		}
+		return false
+	}
+
+	expectNumber, either, reported := true, false, false
+	previousEnd := 0
+	for i, token := range tokens {
+		if hasGap(previousEnd, token.Position) {
+			either = true
+		}
+		previousEnd = tokenEnd(token)
+		reported = false
+
+		role := c.roleOf(token)
+		switch {
+		case either:
+			expectNumber = role != roleValue && role != roleClose
+			either = false
+		case role == roleOpen:
+		case role == roleClose:
+			if expectNumber && (i == 0 || tokens[i-1].Type != LeftParenToken) {
+				errs = append(errs, ParseError{Message: "Expected number", Position: token.Position, End: previousEnd, Location: token.Location})
+			}
+			expectNumber = false
+		case role == rolePrefix && expectNumber:
+		case role != roleInfix && !expectNumber:
+			errs = append(errs, ParseError{Message: "Expected operator", Position: token.Position, End: previousEnd, Location: token.Location})
+		case role == roleInfix && expectNumber:
+			message := "Expected number"
+			if i == 0 {
+				message = "Expression must start with a number"
+			}
+			errs = append(errs, ParseError{Message: message, Position: token.Position, End: previousEnd, Location: token.Location})
+			reported = true
+		default:
+			expectNumber = !expectNumber
+		}
+	}
+
+	// A trailing operator needs a number after it, unless it was already
+	// reported or an invalid character may have been meant as one
+	if len(tokens) > 0 && !reported && !hasGap(previousEnd, math.MaxInt) {
+		last := tokens[len(tokens)-1]
+		if last.Type == OperatorToken {
+			errs = append(errs, ParseError{Message: "Expression must end with a number", Position: last.Position, End: previousEnd, Location: last.Location})
+		}
+	}
+	return errs
+}
+
+// errorPosition returns the start of an error's span, or -1 if it has none
+func errorPosition(err error) int {
+	if source, ok := err.(SourceError); ok {
+		start, _ := source.Span()
+		return start
+	}
+	return -1
+}
@@ -0,0 +1,139 @@
+package contract
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// runJSON runs the CLI with --json and decodes its output
+func runJSON(t *testing.T, args ...string) (map[string]interface{}, error) {
+	t.Helper()
+
+	workDir, _ := os.Getwd()
+	binaryPath := filepath.Join(workDir, "..", "..", "bin", "precise-calc")
+
+	cmd := exec.Command(binaryPath, append([]string{"--json"}, args...)...)
+	output, runErr := cmd.Output()
+
+	decoded := map[string]interface{}{}
+	if err := json.Unmarshal(output, &decoded); err != nil {
+		t.Fatalf("Command %v produced invalid JSON %q: %v", args, output, err)
+	}
+	return decoded, runErr
+}
+
+func TestCLIJSONResult(t *testing.T) {
+	decoded, err := runJSON(t, "0.1 + 0.2")
+	if err != nil {
+		t.Fatalf("Expected success, got error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"expression":  "0.1 + 0.2",
+		"result":      "0.3",
+		"numerator":   "3",
+		"denominator": "10",
+		"decimal":     "0.3",
+		"hex":         "0x0.4(c)",
+		"is_integer":  false,
+	}
+	for key, value := range expected {
+		if decoded[key] != value {
+			t.Errorf("Field %s = %v, want %v", key, decoded[key], value)
+		}
+	}
+
+	decoded, err = runJSON(t, "0x1000 - 0x10")
+	if err != nil {
+		t.Fatalf("Expected success, got error: %v", err)
+	}
+	if decoded["hex"] != "0xff0" || decoded["is_integer"] != true {
+		t.Errorf("Integer result decoded as %v", decoded)
+	}
+}
+
+func TestCLIJSONErrors(t *testing.T) {
+	tests := []struct {
+		expression string
+		errorType  string
+		position   interface{}
+	}{
+		{"5 / 0", "DivisionByZeroError", float64(2)},
+		{"5 + @", "InvalidCharacterError", float64(4)},
+		{"", "EmptyExpressionError", nil},
+		{"5 + + 3", "ParseError", float64(4)},
+	}
+
+	for _, test := range tests {
+		decoded, err := runJSON(t, test.expression)
+		if err == nil {
+			t.Errorf("Expression %q expected failure exit code", test.expression)
+		}
+
+		errorObject, ok := decoded["error"].(map[string]interface{})
+		if !ok {
+			t.Errorf("Expression %q output has no error object: %v", test.expression, decoded)
+			continue
+		}
+		if errorObject["type"] != test.errorType {
+			t.Errorf("Expression %q error type = %v, want %s", test.expression, errorObject["type"], test.errorType)
+		}
+		if errorObject["position"] != test.position {
+			t.Errorf("Expression %q error position = %v, want %v", test.expression, errorObject["position"], test.position)
+		}
+		if errorObject["message"] == "" {
+			t.Errorf("Expression %q error message is empty", test.expression)
+		}
+	}
+}
+
+func TestCLIJSONUsageErrors(t *testing.T) {
+	tests := [][]string{
+		{"--base", "99", "1"},
+		{"--bogus", "1"},
+		{"--hex", "--bin", "5"},
+		{},
+	}
+
+	for _, args := range tests {
+		decoded, err := runJSON(t, args...)
+		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 2 {
+			t.Errorf("Command %v error = %v, want exit code 2", args, err)
+		}
+
+		errorObject, ok := decoded["error"].(map[string]interface{})
+		if !ok {
+			t.Errorf("Command %v output has no error object: %v", args, decoded)
+			continue
+		}
+		if errorObject["type"] != "UsageError" || errorObject["message"] == "" {
+			t.Errorf("Command %v error = %v, want a UsageError with a message", args, errorObject)
+		}
+	}
+}
+
+func TestCLIJSONExplain(t *testing.T) {
+	decoded, err := runJSON(t, "--explain", "0.1 + 0.2 x 3")
+	if err != nil {
+		t.Fatalf("Expected success, got error: %v", err)
+	}
+
+	if decoded["parenthesized"] != "(0.1 + (0.2 x 3))" || decoded["result"] != "0.7" || decoded["exact"] != "0.7" {
+		t.Errorf("Explanation decoded as %v", decoded)
+	}
+	if tokens, _ := decoded["tokens"].([]interface{}); len(tokens) != 5 {
+		t.Errorf("tokens = %v, want 5 tokens", decoded["tokens"])
+	}
+	steps, _ := decoded["steps"].([]interface{})
+	if len(steps) != 2 {
+		t.Fatalf("steps = %v, want 2 steps", decoded["steps"])
+	}
+	first, _ := steps[0].(map[string]interface{})
+	operands, _ := first["operands"].([]interface{})
+	if first["operator"] != "x" || first["position"] != float64(10) || len(operands) != 2 || operands[0] != "0.2" || first["result"] != "0.6" {
+		t.Errorf("First step decoded as %v", first)
+	}
+}
@@ -48,6 +48,89 @@ func TestCLIBasicUsage(t *testing.T) {
 	}
 }
 
+func TestCLIRadixOutput(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"--hex", "0x1000 - 0x10"}, "0xff0"},
+		{[]string{"0x1000 - 0x10", "--hex"}, "0xff0"},
+		{[]string{"--hex", "-0xFF"}, "-0xff"},
+		{[]string{"--bin", "5"}, "0b101"},
+		{[]string{"--oct", "8"}, "0o10"},
+		{[]string{"--base", "36", "35"}, "z"},
+		{[]string{"--base=10", "1/3"}, "0.(3)"},
+		{[]string{"--hex", "--no-prefix", "255"}, "ff"},
+		{[]string{"--base", "auto", "0x1000 - 0x10"}, "0xff0"},
+		{[]string{"--base", "auto", "0b101 + 0b1"}, "0b110"},
+		{[]string{"--base", "auto", "0xFF + 1"}, "256"},
+		{[]string{"--base", "auto", "0xF / 0x2"}, "7.5"},
+	}
+
+	for _, test := range tests {
+		workDir, _ := os.Getwd()
+		binaryPath := filepath.Join(workDir, "..", "..", "bin", "precise-calc")
+
+		cmd := exec.Command(binaryPath, test.args...)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Errorf("Command %v expected success, got error: %v (%s)", test.args, err, output)
+			continue
+		}
+
+		outputStr := strings.TrimSpace(string(output))
+		if outputStr != test.expected {
+			t.Errorf("Command %v output %q, want %q", test.args, outputStr, test.expected)
+		}
+	}
+}
+
+func TestCLINotationOutput(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"--sci", "--digits", "4", "1 / 10000000000000000000000000000000000000000"}, "1.000e-40"},
+		{[]string{"--sci", "--digits", "3", "12345"}, "1.23e+4"},
+		{[]string{"--eng", "--digits", "3", "12345"}, "12.3e+3"},
+		{[]string{"--sci", "-0.5"}, "-5.000000000e-1"},
+		{[]string{"--locale", "de-DE", "1234567.89"}, "1.234.567,89"},
+		{[]string{"--locale", "en-IN", "1234567.89"}, "12,34,567.89"},
+		{[]string{"--format", "#,##0.00;(#,##0.00)", "0 - 1234.5"}, "(1,234.50)"},
+		{[]string{"--format", "#,##0.00", "--locale", "de-DE", "2/3"}, "0,67"},
+		{[]string{"--fraction", "mixed", "7/3"}, "2 1/3"},
+		{[]string{"--fraction", "unicode", "7/3"}, "2⅓"},
+		{[]string{"--mixed-input", "--fraction", "mixed", "2 1/3 + 1 1/3"}, "3 2/3"},
+		{[]string{"--sig-figs", "3", "1234.5"}, "1230"},
+		{[]string{"--track-sig-figs", "2.50 x 3.1"}, "7.8"},
+		{[]string{"--track-sig-figs", "12.11 + 18.0 + 1.013"}, "31.1"},
+		{[]string{"--decimal", "1.10 + 2.20"}, "3.30"},
+		{[]string{"--decimal", "--rounding", "half-even", "10.00 / 3"}, "3.33"},
+		{[]string{"--decimal", "--rounding", "half-up", "10 / 3"}, "3"},
+		{[]string{"--decimal", "--scale", "0", "10 / 3"}, "3"},
+		{[]string{"--decimal", "--scale", "4", "--rounding", "down", "2 / 3"}, "0.6666"},
+		{[]string{"--numeric", "18,2", "10 / 3 x 3"}, "9.99"},
+		{[]string{"--numeric", "5,2", "--rounding", "down", "2 / 3"}, "0.66"},
+	}
+
+	for _, test := range tests {
+		workDir, _ := os.Getwd()
+		binaryPath := filepath.Join(workDir, "..", "..", "bin", "precise-calc")
+
+		cmd := exec.Command(binaryPath, test.args...)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Errorf("Command %v expected success, got error: %v (%s)", test.args, err, output)
+			continue
+		}
+
+		outputStr := strings.TrimSpace(string(output))
+		if outputStr != test.expected {
+			t.Errorf("Command %v output %q, want %q", test.args, outputStr, test.expected)
+		}
+	}
+}
+
 func TestCLIErrorCases(t *testing.T) {
 	tests := []struct {
 		args        []string
@@ -59,6 +142,20 @@ func TestCLIErrorCases(t *testing.T) {
 		{[]string{""}, "Error", true},
 		{[]string{"5 + + 3"}, "Error", true},
 		{[]string{"0xGHI + 5"}, "Error", true},
+		{[]string{"--base", "40", "5"}, "Error", true},
+		{[]string{"--hex", "--bin", "5"}, "Error", true},
+		{[]string{"--sci", "--eng", "5"}, "Error", true},
+		{[]string{"--sci", "--digits", "0", "5"}, "Error", true},
+		{[]string{"--locale", "xx-XX", "5"}, "Error", true},
+		{[]string{"--format", "0.0.0", "5"}, "Error", true},
+		{[]string{"--fraction", "decimal", "5"}, "Error", true},
+		{[]string{"2 1/3"}, "Error", true},
+		{[]string{"--sig-figs", "3", "--sci", "5"}, "Error", true},
+		{[]string{"--decimal", "--rounding", "unnecessary", "2 / 3"}, "Error", true},
+		{[]string{"--decimal", "--rounding", "sideways", "1"}, "Error", true},
+		{[]string{"--numeric", "5,2", "999.99 + 0.01"}, "Error", true},
+		{[]string{"--numeric", "3,5", "1"}, "Error", true},
+		{[]string{"--numeric", "18", "1"}, "Error", true},
 	}
 
 	for _, test := range tests {
@@ -82,3 +179,103 @@ func TestCLIErrorCases(t *testing.T) {
 		}
 	}
 }
+
+func TestCLIErrorSnippet(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"5 + + 3"}, "Error: Expected number at position 4\n  5 + + 3\n      ^"},
+		{[]string{"  1 / 0"}, "Error: Division by zero\n    1 / 0\n      ^"},
+		{[]string{"1 + 0x"}, "  1 + 0x\n      ^~"},
+		{[]string{"--color", "5 + @"}, "  \x1b[1;31m    ^\x1b[0m"},
+		{[]string{"1 +\n  2 / 0"}, "  --> line 2, column 5\n  2 |   2 / 0\n    |     ^"},
+		{[]string{"3 * 4"}, "  3 * 4\n    ^\nHint: use 'x' for multiplication"},
+		{[]string{"--all-errors", "1 @ + + 2"}, "Error: Invalid character '@' at position 2\n  1 @ + + 2\n    ^\nError: Expected number at position 6\n  1 @ + + 2\n        ^"},
+	}
+
+	for _, test := range tests {
+		workDir, _ := os.Getwd()
+		binaryPath := filepath.Join(workDir, "..", "..", "bin", "precise-calc")
+
+		cmd := exec.Command(binaryPath, test.args...)
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			t.Errorf("Command %v expected to fail, got success", test.args)
+			continue
+		}
+
+		if !strings.Contains(string(output), test.expected) {
+			t.Errorf("Command %v output %q does not contain %q", test.args, output, test.expected)
+		}
+	}
+}
+
+func TestCLIExitCodes(t *testing.T) {
+	tests := []struct {
+		args     []string
+		exitCode int
+	}{
+		{[]string{"5 + 3"}, 0},
+		{[]string{"5 + @"}, 1},
+		{[]string{"5 + + 3"}, 1},
+		{[]string{"--bogus", "5"}, 2},
+		{[]string{"--base", "40", "5"}, 2},
+		{[]string{"5 / 0"}, 3},
+		{[]string{"--numeric", "5,2", "999.99 + 0.01"}, 3},
+		{[]string{"--decimal", "--rounding", "unnecessary", "2 / 3"}, 3},
+		{[]string{"--decimal", "2 / 3"}, 3},
+		{[]string{"--safe", "1 + 2"}, 0},
+		{[]string{"--safe", strings.Repeat("1 + ", 1000) + "1"}, 5},
+		{[]string{"--safe", "--decimal", "1.50 + 1"}, 0},
+		{[]string{"--safe", "--decimal", strings.Repeat("1 + ", 1000) + "1"}, 5},
+		{[]string{"--safe", "--numeric", "18,2", strings.Repeat("1 + ", 1000) + "1"}, 5},
+	}
+
+	for _, test := range tests {
+		workDir, _ := os.Getwd()
+		binaryPath := filepath.Join(workDir, "..", "..", "bin", "precise-calc")
+
+		err := exec.Command(binaryPath, test.args...).Run()
+		code := 0
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			code = exitErr.ExitCode()
+		} else if err != nil {
+			t.Fatalf("Command %v failed to run: %v", test.args, err)
+		}
+
+		if code != test.exitCode {
+			t.Errorf("Command %v exit code = %d, want %d", test.args, code, test.exitCode)
+		}
+	}
+}
+
+func TestCLIExplain(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+		exitCode int
+	}{
+		{[]string{"--explain", "1 + 2 x 3"}, "Tokens:        [1] [+] [2] [x] [3]\nPostfix:       1 2 3 x +\nParenthesized: (1 + (2 x 3))\nSteps:\n  1. 2 x 3 = 6\n  2. 1 + 6 = 7\nResult:        7", 0},
+		{[]string{"--explain", "--hex", "0.5 x 0x20"}, "  1. 0.5 x 32 = 16\nResult:        0x10", 0},
+		{[]string{"--explain", "1 + 2 - 3 / 0"}, "  1. 1 + 2 = 3\nError: Division by zero", 3},
+		{[]string{"--explain", "--decimal", "1"}, "--explain cannot be combined", 2},
+	}
+
+	for _, test := range tests {
+		workDir, _ := os.Getwd()
+		binaryPath := filepath.Join(workDir, "..", "..", "bin", "precise-calc")
+
+		output, err := exec.Command(binaryPath, test.args...).CombinedOutput()
+		code := 0
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			code = exitErr.ExitCode()
+		}
+		if code != test.exitCode {
+			t.Errorf("Command %v exit code = %d, want %d", test.args, code, test.exitCode)
+		}
+		if !strings.Contains(string(output), test.expected) {
+			t.Errorf("Command %v output %q does not contain %q", test.args, output, test.expected)
+		}
+	}
+}
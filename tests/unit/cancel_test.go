@@ -0,0 +1,116 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"precise-calc/pkg/calculator"
+	"strings"
+	"testing"
+	"time"
+)
+
+// expiringContext reports no error for its first checks and then reports
+// that its deadline has passed
+type expiringContext struct {
+	context.Context
+	checks int
+}
+
+func (c *expiringContext) Err() error {
+	if c.checks == 0 {
+		return context.DeadlineExceeded
+	}
+	c.checks--
+	return nil
+}
+
+func TestCalculateContext(t *testing.T) {
+	result, err := calculator.CalculateContext(context.Background(), "0.1 + 0.2")
+	if err != nil || result.RatString() != "3/10" {
+		t.Errorf("CalculateContext = %v, %v; want 3/10", result, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if _, err := calculator.CalculateContext(ctx, "1 / 0"); !errors.Is(err, calculator.ErrDivisionByZero) {
+		t.Errorf("CalculateContext(1 / 0) error = %v, want division by zero", err)
+	}
+}
+
+func TestCalculateContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := calculator.CalculateContext(ctx, "1 + 2")
+	var canceled calculator.CanceledError
+	if !errors.As(err, &canceled) {
+		t.Fatalf("error = %v, want CanceledError", err)
+	}
+	if !errors.Is(err, context.Canceled) || !errors.Is(err, calculator.ErrCanceled) {
+		t.Errorf("error %v should match context.Canceled and ErrCanceled", err)
+	}
+	if calculator.CodeOf(err) != calculator.CodeCanceled {
+		t.Errorf("code = %s, want %s", calculator.CodeOf(err), calculator.CodeCanceled)
+	}
+}
+
+func TestCalculateContextStopsBetweenOperations(t *testing.T) {
+	// Allow the initial check and three operations, then expire
+	ctx := &expiringContext{Context: context.Background(), checks: 4}
+	expression := "1" + strings.Repeat(" + 1", 10)
+
+	_, err := calculator.CalculateContext(ctx, expression)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error = %v, want context.DeadlineExceeded", err)
+	}
+	if ctx.checks != 0 {
+		t.Errorf("evaluation stopped with %d checks left", ctx.checks)
+	}
+}
+
+func TestModeContextStopsBetweenOperations(t *testing.T) {
+	c, err := calculator.New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	numeric, err := calculator.NewFixedDecimal(18, 2)
+	if err != nil {
+		t.Fatalf("NewFixedDecimal() error = %v", err)
+	}
+	expression := "1.5" + strings.Repeat(" + 1", 10)
+
+	modes := []struct {
+		name      string
+		calculate func(ctx context.Context) error
+	}{
+		{"decimal", func(ctx context.Context) error {
+			_, err := c.CalculateDecimalContext(ctx, expression, calculator.DivisionContext{})
+			return err
+		}},
+		{"significant", func(ctx context.Context) error {
+			_, err := c.CalculateSignificantContext(ctx, expression)
+			return err
+		}},
+		{"fixed", func(ctx context.Context) error {
+			_, err := c.CalculateFixedContext(ctx, expression, numeric)
+			return err
+		}},
+		{"context", func(ctx context.Context) error {
+			_, err := c.CalculateInContext(ctx, expression, calculator.DefaultContext)
+			return err
+		}},
+	}
+	for _, mode := range modes {
+		if err := mode.calculate(context.Background()); err != nil {
+			t.Errorf("%s: error = %v", mode.name, err)
+		}
+
+		ctx := &expiringContext{Context: context.Background(), checks: 4}
+		if err := mode.calculate(ctx); !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, calculator.ErrCanceled) {
+			t.Errorf("%s: error = %v, want context.DeadlineExceeded", mode.name, err)
+		}
+		if ctx.checks != 0 {
+			t.Errorf("%s: evaluation stopped with %d checks left", mode.name, ctx.checks)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package unit
+
+import (
+	"errors"
+	"precise-calc/pkg/calculator"
+	"testing"
+)
+
+func TestContextCalculate(t *testing.T) {
+	tests := []struct {
+		ctx        calculator.Context
+		expression string
+		expected   string
+		flags      calculator.Signal
+	}{
+		{calculator.DefaultContext, "0.1 + 0.2", "3/10", 0},
+		{calculator.Context{Precision: 3}, "2 / 3", "667/1000", calculator.SignalInexact | calculator.SignalRounded},
+		{calculator.Context{Precision: 3, Rounding: calculator.RoundDown}, "2 / 3", "333/500", calculator.SignalInexact | calculator.SignalRounded},
+		{calculator.Context{Precision: 2, Rounding: calculator.RoundHalfEven}, "1.25 + 0", "6/5", calculator.SignalInexact | calculator.SignalRounded},
+		{calculator.Context{Precision: 3}, "1.25 x 2", "5/2", 0},
+		{calculator.Context{}, "1 / 3", "1/3", 0},
+		{calculator.Context{Precision: 2, Emin: -2}, "1 / 3000", "0", calculator.SignalSubnormal | calculator.SignalUnderflow | calculator.SignalInexact | calculator.SignalRounded},
+		{calculator.Context{Precision: 2, Emin: -2}, "1 / 500", "1/500", calculator.SignalSubnormal},
+	}
+
+	for _, test := range tests {
+		result, err := test.ctx.Calculate(test.expression)
+		if err != nil {
+			t.Errorf("Calculate(%s) error: %v", test.expression, err)
+			continue
+		}
+		if result.Value == nil || result.Value.RatString() != test.expected {
+			t.Errorf("Calculate(%s) = %v, want %s", test.expression, result.Value, test.expected)
+		}
+		if result.Flags != test.flags {
+			t.Errorf("Calculate(%s) flags = %s, want %s", test.expression, result.Flags, test.flags)
+		}
+	}
+}
+
+func TestContextUntrappedSignals(t *testing.T) {
+	tests := []struct {
+		expression string
+		flags      calculator.Signal
+	}{
+		{"1 / 0", calculator.SignalDivisionByZero},
+		{"0 / 0 + 1", calculator.SignalInvalidOperation},
+		{"999 x 999", calculator.SignalOverflow | calculator.SignalInexact | calculator.SignalRounded},
+	}
+
+	ctx := calculator.Context{Precision: 3, Emax: 3}
+	for _, test := range tests {
+		result, err := ctx.Calculate(test.expression)
+		if err != nil {
+			t.Errorf("Calculate(%s) error: %v", test.expression, err)
+			continue
+		}
+		if result.Value != nil {
+			t.Errorf("Calculate(%s) = %s, want nil value", test.expression, result.Value.RatString())
+		}
+		if result.Flags != test.flags {
+			t.Errorf("Calculate(%s) flags = %s, want %s", test.expression, result.Flags, test.flags)
+		}
+	}
+}
+
+func TestContextTraps(t *testing.T) {
+	_, err := calculator.DefaultContext.Calculate("1 + 2 / 0")
+	if e, ok := err.(calculator.DivisionByZeroError); !ok || e.Position != 6 {
+		t.Errorf("Calculate(1 + 2 / 0) error = %v, want DivisionByZeroError at 6", err)
+	}
+
+	tests := []struct {
+		ctx        calculator.Context
+		expression string
+		signal     calculator.Signal
+		position   int
+	}{
+		{calculator.DefaultContext, "0 / 0", calculator.SignalInvalidOperation, 2},
+		{calculator.Context{Precision: 3, Traps: calculator.SignalInexact}, "1 + 1 / 3", calculator.SignalInexact, 6},
+		{calculator.Context{Precision: 3, Emax: 3, Traps: calculator.SignalOverflow}, "999 x 999", calculator.SignalOverflow, 4},
+	}
+
+	for _, test := range tests {
+		result, err := test.ctx.Calculate(test.expression)
+		var signal calculator.SignalError
+		if !errors.As(err, &signal) {
+			t.Errorf("Calculate(%s) error = %v, want SignalError", test.expression, err)
+			continue
+		}
+		if signal.Signal != test.signal || signal.Position != test.position {
+			t.Errorf("Calculate(%s) = %v, want %s at %d", test.expression, signal, test.signal, test.position)
+		}
+		if !result.Flags.Has(test.signal) {
+			t.Errorf("Calculate(%s) flags = %s, want %s recorded", test.expression, result.Flags, test.signal)
+		}
+	}
+}
+
+func TestSignalString(t *testing.T) {
+	if s := (calculator.SignalInexact | calculator.SignalRounded).String(); s != "Inexact|Rounded" {
+		t.Errorf("String() = %s, want Inexact|Rounded", s)
+	}
+	if s := calculator.Signal(0).String(); s != "0" {
+		t.Errorf("String() = %s, want 0", s)
+	}
+}
@@ -0,0 +1,79 @@
+package unit
+
+import (
+	"errors"
+	"math/big"
+	"precise-calc/pkg/calculator"
+	"testing"
+)
+
+func TestErrorCodesAndSentinels(t *testing.T) {
+	tests := []struct {
+		expression string
+		code       calculator.ErrorCode
+		kind       error
+		category   error
+	}{
+		{"1 + + 2", calculator.CodeParse, calculator.ErrParse, calculator.ErrSyntax},
+		{"1 + @", calculator.CodeInvalidCharacter, calculator.ErrInvalidCharacter, calculator.ErrSyntax},
+		{"   ", calculator.CodeEmptyExpression, calculator.ErrEmptyExpression, calculator.ErrSyntax},
+		{"1 / 0", calculator.CodeDivisionByZero, calculator.ErrDivisionByZero, calculator.ErrArithmetic},
+	}
+
+	for _, test := range tests {
+		_, err := calculator.Calculate(test.expression)
+		if code := calculator.CodeOf(err); code != test.code {
+			t.Errorf("Calculate(%q) code = %q, want %q", test.expression, code, test.code)
+		}
+		if !errors.Is(err, test.kind) || !errors.Is(err, test.category) {
+			t.Errorf("Calculate(%q) error %v should match %v and %v", test.expression, err, test.kind, test.category)
+		}
+		if errors.Is(err, calculator.ErrConfiguration) {
+			t.Errorf("Calculate(%q) error %v should not be a configuration error", test.expression, err)
+		}
+	}
+}
+
+func TestModeErrorCategories(t *testing.T) {
+	fixed, _ := calculator.NewFixedDecimal(3, 0)
+	_, err := fixed.Calculate("999 + 1")
+	if !errors.Is(err, calculator.ErrOverflow) || !errors.Is(err, calculator.ErrArithmetic) {
+		t.Errorf("overflow error %v should match ErrOverflow and ErrArithmetic", err)
+	}
+
+	_, err = calculator.CalculateDecimal("2 / 3", calculator.DivisionContext{Rounding: calculator.RoundUnnecessary})
+	if calculator.CodeOf(err) != calculator.CodeRoundingRequired || !errors.Is(err, calculator.ErrArithmetic) {
+		t.Errorf("rounding error %v should have code %s", err, calculator.CodeRoundingRequired)
+	}
+
+	_, err = calculator.FormatRadix(big.NewRat(1, 1), 40)
+	if !errors.Is(err, calculator.ErrInvalidBase) || !errors.Is(err, calculator.ErrConfiguration) {
+		t.Errorf("base error %v should match ErrInvalidBase and ErrConfiguration", err)
+	}
+}
+
+func TestParseErrorWrapsNumberFailure(t *testing.T) {
+	_, err := calculator.Calculate("1 + 0x")
+	var parseErr calculator.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Message != "Invalid number format: 0x" {
+		t.Fatalf("Calculate error = %v, want invalid number format", err)
+	}
+
+	inner := errors.Unwrap(err)
+	if inner == nil || inner.Error() != "Parse error at position 2: No hex digits after 0x" {
+		t.Errorf("Unwrap = %v, want the ParseHexadecimal failure", inner)
+	}
+}
+
+func TestErrorListCode(t *testing.T) {
+	err := calculator.ValidateAll("1 @ 2 / ", calculator.Options{})
+	if calculator.CodeOf(err) != calculator.CodeInvalidCharacter {
+		t.Errorf("CodeOf(%v) = %q, want code of the first error", err, calculator.CodeOf(err))
+	}
+	if !errors.Is(err, calculator.ErrParse) || !errors.Is(err, calculator.ErrSyntax) {
+		t.Errorf("ErrorList %v should match the sentinels of its elements", err)
+	}
+	if calculator.CodeOf(errors.New("other")) != "" {
+		t.Errorf("CodeOf of a foreign error should be empty")
+	}
+}
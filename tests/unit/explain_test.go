@@ -0,0 +1,86 @@
+package unit
+
+import (
+	"errors"
+	"math/big"
+	"precise-calc/pkg/calculator"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		expression    string
+		parenthesized string
+		steps         []string
+		result        string
+	}{
+		{"1 + 2 x 3", "(1 + (2 x 3))", []string{"2 x 3 = 6", "1 + 6 = 7"}, "7"},
+		{"8 - 4 - 2", "((8 - 4) - 2)", []string{"8 - 4 = 4", "4 - 2 = 2"}, "2"},
+		{"0.1 + 1 / 3", "(0.1 + (1 / 3))", []string{"1 / 3 = 1/3", "0.1 + 1/3 = 13/30"}, "13/30"},
+		{"0xFF", "0xFF", nil, "255"},
+	}
+
+	for _, test := range tests {
+		explanation, err := calculator.Explain(test.expression)
+		if err != nil {
+			t.Errorf("Explain(%q) error = %v", test.expression, err)
+			continue
+		}
+		if explanation.Parenthesized != test.parenthesized {
+			t.Errorf("Explain(%q) parenthesized = %q, want %q", test.expression, explanation.Parenthesized, test.parenthesized)
+		}
+		if len(explanation.Steps) != len(test.steps) {
+			t.Errorf("Explain(%q) steps = %v, want %v", test.expression, explanation.Steps, test.steps)
+			continue
+		}
+		for i, step := range explanation.Steps {
+			if step.String() != test.steps[i] {
+				t.Errorf("Explain(%q) step %d = %q, want %q", test.expression, i+1, step, test.steps[i])
+			}
+		}
+		if got := calculator.FormatRational(explanation.Result); got != test.result {
+			t.Errorf("Explain(%q) result = %s, want %s", test.expression, got, test.result)
+		}
+
+		// The trace matches what InfixToPostfix and EvaluatePostfix produce
+		postfix, _ := calculator.InfixToPostfix(explanation.Tokens)
+		result, _ := calculator.EvaluatePostfix(postfix)
+		if len(postfix) != len(explanation.Postfix) || result.Cmp(explanation.Result) != 0 {
+			t.Errorf("Explain(%q) disagrees with InfixToPostfix and EvaluatePostfix", test.expression)
+		}
+	}
+}
+
+func TestExplainCustom(t *testing.T) {
+	c := functionCalculator(t)
+	explanation, err := c.Explain("fx(\"EUR\", tax(50)) x 2")
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	expected := []string{"tax(50) = 10", "fx(\"EUR\", 10) = 11", "11 x 2 = 22"}
+	if explanation.Parenthesized != "(fx(\"EUR\", tax(50)) x 2)" || len(explanation.Steps) != len(expected) {
+		t.Fatalf("Explain() = %q, %v", explanation.Parenthesized, explanation.Steps)
+	}
+	for i, step := range explanation.Steps {
+		if step.String() != expected[i] {
+			t.Errorf("step %d = %q, want %q", i+1, step, expected[i])
+		}
+	}
+	if step := explanation.Steps[1]; !step.Operands[0].IsText() || step.Operands[1].Value.Cmp(big.NewRat(10, 1)) != 0 {
+		t.Errorf("step 2 operands = %v", step.Operands)
+	}
+}
+
+func TestExplainErrors(t *testing.T) {
+	explanation, err := calculator.Explain("2 x 3 + 1 / 0")
+	if !errors.Is(err, calculator.ErrDivisionByZero) {
+		t.Fatalf("Explain() error = %v, want ErrDivisionByZero", err)
+	}
+	if explanation == nil || len(explanation.Steps) != 1 || explanation.Steps[0].String() != "2 x 3 = 6" || explanation.Result != nil {
+		t.Errorf("Explain() = %+v, want the steps before the error", explanation)
+	}
+
+	if explanation, err := calculator.Explain("2 +"); explanation != nil || !errors.Is(err, calculator.ErrSyntax) {
+		t.Errorf("Explain(\"2 +\") = %v, %v, want a syntax error only", explanation, err)
+	}
+}
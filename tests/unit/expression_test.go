@@ -0,0 +1,65 @@
+package unit
+
+import (
+	"errors"
+	"math/big"
+	"precise-calc/pkg/calculator"
+	"testing"
+)
+
+func TestCalculateExpression(t *testing.T) {
+	expr, err := calculator.CalculateExpression("0xFF + 0b10 x 1.50")
+	if err != nil {
+		t.Fatalf("CalculateExpression() error = %v", err)
+	}
+	if expr.Original != "0xFF + 0b10 x 1.50" || len(expr.Tokens) != 5 || len(expr.PostfixTokens) != 5 {
+		t.Errorf("CalculateExpression() = %+v", expr)
+	}
+	if expr.Result == nil || expr.Result.Cmp(big.NewRat(258, 1)) != 0 {
+		t.Errorf("Result = %v, want 258", expr.Result)
+	}
+
+	expected := []struct {
+		original string
+		kind     calculator.NumberType
+		value    *big.Rat
+	}{
+		{"0xFF", calculator.Hexadecimal, big.NewRat(255, 1)},
+		{"0b10", calculator.Binary, big.NewRat(2, 1)},
+		{"1.50", calculator.Decimal, big.NewRat(3, 2)},
+	}
+	if len(expr.Numbers) != len(expected) {
+		t.Fatalf("Numbers = %v, want %d numbers", expr.Numbers, len(expected))
+	}
+	for i, want := range expected {
+		got := expr.Numbers[i]
+		if got.Original != want.original || got.Type != want.kind || got.Value.Cmp(want.value) != 0 {
+			t.Errorf("Numbers[%d] = %+v, want %s", i, got, want.original)
+		}
+	}
+
+	// The record matches what Calculate returns
+	result, _ := calculator.Calculate(expr.Original)
+	if result.Cmp(expr.Result) != 0 {
+		t.Errorf("Calculate() = %v, CalculateExpression() = %v", result, expr.Result)
+	}
+
+	mixed, err := calculator.CalculateExpressionWithOptions("2 1/2 x 2", calculator.Options{MixedNumbers: true})
+	if err != nil || len(mixed.Numbers) != 2 || mixed.Numbers[0].Type != calculator.Mixed || mixed.Numbers[0].Original != "2 1/2" {
+		t.Errorf("CalculateExpressionWithOptions() = %+v, %v", mixed, err)
+	}
+}
+
+func TestCalculateExpressionErrors(t *testing.T) {
+	expr, err := calculator.CalculateExpression("1 + 2 / 0")
+	if !errors.Is(err, calculator.ErrDivisionByZero) {
+		t.Fatalf("CalculateExpression() error = %v, want ErrDivisionByZero", err)
+	}
+	if expr == nil || expr.Result != nil || len(expr.Numbers) != 3 || len(expr.PostfixTokens) != 5 {
+		t.Errorf("CalculateExpression() = %+v, want the parsed record without a result", expr)
+	}
+
+	if expr, err := calculator.CalculateExpression("1 +"); expr != nil || !errors.Is(err, calculator.ErrSyntax) {
+		t.Errorf("CalculateExpression(\"1 +\") = %v, %v, want a syntax error only", expr, err)
+	}
+}
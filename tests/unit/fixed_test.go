@@ -0,0 +1,102 @@
+package unit
+
+import (
+	"errors"
+	"math/big"
+	"precise-calc/pkg/calculator"
+	"testing"
+)
+
+func TestFixedDecimalCalculate(t *testing.T) {
+	tests := []struct {
+		precision  int
+		scale      int
+		rounding   calculator.RoundingMode
+		expression string
+		expected   string
+	}{
+		{18, 2, calculator.RoundHalfUp, "10 / 3 x 3", "9.99"},
+		{18, 2, calculator.RoundHalfUp, "1.005 + 1", "2.01"},
+		{18, 2, calculator.RoundHalfEven, "1.005 + 1", "2.00"},
+		{18, 2, calculator.RoundDown, "2 / 3", "0.66"},
+		{18, 0, calculator.RoundHalfUp, "7 / 2", "4"},
+		{5, 2, calculator.RoundHalfUp, "999.98 + 0.01", "999.99"},
+		{5, 2, calculator.RoundHalfUp, "0 - 999.99", "-999.99"},
+		{18, 2, calculator.RoundUnnecessary, "1.25 x 4", "5.00"},
+	}
+
+	for _, test := range tests {
+		fixed := calculator.FixedDecimal{Precision: test.precision, Scale: test.scale, Rounding: test.rounding}
+		result, err := fixed.Calculate(test.expression)
+		if err != nil {
+			t.Errorf("%s %s Calculate(%s) error: %v", fixed, test.rounding, test.expression, err)
+			continue
+		}
+		if result.String() != test.expected || result.Scale != test.scale {
+			t.Errorf("%s %s Calculate(%s) = %s, want %s", fixed, test.rounding, test.expression, result, test.expected)
+		}
+	}
+}
+
+func TestFixedDecimalOverflow(t *testing.T) {
+	tests := []struct {
+		expression string
+		position   int
+	}{
+		{"999.99 + 0.01", 7},
+		{"100 x 10", 4},
+		{"1 + 1000", 4},
+		{"10 / 0.01", 3},
+	}
+
+	fixed, err := calculator.NewFixedDecimal(5, 2)
+	if err != nil {
+		t.Fatalf("NewFixedDecimal(5, 2) error: %v", err)
+	}
+
+	for _, test := range tests {
+		_, err := fixed.Calculate(test.expression)
+		var overflow calculator.OverflowError
+		if !errors.As(err, &overflow) {
+			t.Errorf("Calculate(%s) error = %v, want OverflowError", test.expression, err)
+			continue
+		}
+		if overflow.Position != test.position || overflow.Precision != 5 || overflow.Scale != 2 {
+			t.Errorf("Calculate(%s) = %+v, want position %d", test.expression, overflow, test.position)
+		}
+	}
+}
+
+func TestFixedDecimalErrors(t *testing.T) {
+	fixed := calculator.FixedDecimal{Precision: 18, Scale: 2, Rounding: calculator.RoundUnnecessary}
+	_, err := fixed.Calculate("10 / 3")
+	var rounding calculator.RoundingRequiredError
+	if !errors.As(err, &rounding) || rounding.Position != 3 {
+		t.Errorf("Calculate(10 / 3) error = %v, want RoundingRequiredError at 3", err)
+	}
+
+	_, err = fixed.Calculate("1 / 0")
+	if _, ok := err.(calculator.DivisionByZeroError); !ok {
+		t.Errorf("Calculate(1 / 0) error = %v, want DivisionByZeroError", err)
+	}
+
+	for _, spec := range [][2]int{{0, 0}, {3, 5}, {5, -1}} {
+		if _, err := calculator.NewFixedDecimal(spec[0], spec[1]); err == nil {
+			t.Errorf("NewFixedDecimal(%d, %d) expected error", spec[0], spec[1])
+		}
+	}
+}
+
+func TestFixedDecimalFit(t *testing.T) {
+	fixed, _ := calculator.NewFixedDecimal(4, 1)
+	d, err := fixed.Fit(big.NewRat(12345, 100))
+	if err != nil || d.String() != "123.5" {
+		t.Errorf("Fit(123.45) = %s, %v; want 123.5", d, err)
+	}
+	if _, err := fixed.Fit(big.NewRat(99999, 10)); err == nil {
+		t.Errorf("Fit(9999.9) expected overflow")
+	}
+	if fixed.String() != "NUMERIC(4,1)" {
+		t.Errorf("String() = %s, want NUMERIC(4,1)", fixed)
+	}
+}
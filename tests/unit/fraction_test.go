@@ -0,0 +1,117 @@
+package unit
+
+import (
+	"math/big"
+	"precise-calc/pkg/calculator"
+	"testing"
+)
+
+func TestFormatMixed(t *testing.T) {
+	tests := []struct {
+		value    *big.Rat
+		expected string
+	}{
+		{big.NewRat(7, 3), "2 1/3"},
+		{big.NewRat(-7, 3), "-2 1/3"},
+		{big.NewRat(1, 3), "1/3"},
+		{big.NewRat(-1, 3), "-1/3"},
+		{big.NewRat(6, 3), "2"},
+		{big.NewRat(0, 1), "0"},
+	}
+
+	for _, test := range tests {
+		if result := calculator.FormatMixed(test.value); result != test.expected {
+			t.Errorf("FormatMixed(%s) = %q, want %q", test.value, result, test.expected)
+		}
+	}
+}
+
+func TestFormatUnicodeFraction(t *testing.T) {
+	tests := []struct {
+		value    *big.Rat
+		expected string
+	}{
+		{big.NewRat(7, 3), "2⅓"},
+		{big.NewRat(-3, 4), "-¾"},
+		{big.NewRat(19, 12), "1⁷⁄₁₂"},
+		{big.NewRat(5, 1), "5"},
+	}
+
+	for _, test := range tests {
+		if result := calculator.FormatUnicodeFraction(test.value); result != test.expected {
+			t.Errorf("FormatUnicodeFraction(%s) = %q, want %q", test.value, result, test.expected)
+		}
+	}
+}
+
+func TestParseMixedNumber(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectError bool
+	}{
+		{"2 1/3", "7/3", false},
+		{"-2 1/2", "-5/2", false},
+		{"10\t3/4", "43/4", false},
+		{"2 4/3", "", true},
+		{"2 1/0", "", true},
+		{"2", "", true},
+		{"1.5 1/2", "", true},
+	}
+
+	for _, test := range tests {
+		result, err := calculator.ParseMixedNumber(test.input)
+		if test.expectError {
+			if err == nil {
+				t.Errorf("ParseMixedNumber(%q) expected error, got none", test.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseMixedNumber(%q) unexpected error: %v", test.input, err)
+			continue
+		}
+		if result.String() != test.expected {
+			t.Errorf("ParseMixedNumber(%q) = %s, want %s", test.input, result, test.expected)
+		}
+	}
+}
+
+func TestCalculateMixedNumberInput(t *testing.T) {
+	opts := calculator.Options{MixedNumbers: true}
+
+	tests := []struct {
+		expression string
+		expected   string
+	}{
+		{"2 1/3 x 3", "7"},
+		{"6 / 1 1/2", "4"},
+		{"-2 1/2 + 1", "-3/2"},
+		{"1/2 + 1/2", "1"},
+		{"2 1/3+1", "10/3"},
+	}
+
+	for _, test := range tests {
+		result, err := calculator.CalculateWithOptions(test.expression, opts)
+		if err != nil {
+			t.Errorf("CalculateWithOptions(%s) error: %v", test.expression, err)
+			continue
+		}
+		if formatted := calculator.FormatRational(result); formatted != test.expected {
+			t.Errorf("CalculateWithOptions(%s) = %s, want %s", test.expression, formatted, test.expected)
+		}
+	}
+
+	// Without the option a mixed number is a syntax error
+	if _, err := calculator.Calculate("2 1/3"); err == nil {
+		t.Errorf("Calculate(2 1/3) expected error without MixedNumbers")
+	}
+
+	tokens, err := calculator.TokenizeWithOptions("2 1/3 + 1", opts)
+	if err != nil {
+		t.Fatalf("TokenizeWithOptions error: %v", err)
+	}
+	if tokens[0].Value != "2 1/3" || tokens[0].Number == nil || tokens[0].Number.Type != calculator.Mixed {
+		t.Errorf("First token = %+v, want mixed number literal %q", tokens[0], "2 1/3")
+	}
+}
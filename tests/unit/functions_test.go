@@ -0,0 +1,192 @@
+package unit
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"precise-calc/pkg/calculator"
+	"strings"
+	"testing"
+)
+
+var errUnknownCurrency = errors.New("unknown currency")
+
+// functionCalculator registers tax, fx, max and pi for the tests below
+func functionCalculator(t *testing.T) *calculator.Calculator {
+	t.Helper()
+	rates := map[string]*big.Rat{"EUR": big.NewRat(11, 10), "GBP": big.NewRat(5, 4)}
+
+	c, err := calculator.New(
+		calculator.WithFunction(calculator.Function{
+			Name:    "tax",
+			MinArgs: 1,
+			Apply: func(args ...*big.Rat) (*big.Rat, error) {
+				return new(big.Rat).Mul(args[0], big.NewRat(1, 5)), nil
+			},
+		}),
+		calculator.WithFunction(calculator.Function{
+			Name:    "fx",
+			MinArgs: 2,
+			ApplyText: func(args ...calculator.Argument) (*big.Rat, error) {
+				if !args[0].IsText() || args[1].IsText() {
+					return nil, errors.New("usage: fx(\"CODE\", amount)")
+				}
+				rate, ok := rates[args[0].Text]
+				if !ok {
+					return nil, fmt.Errorf("%w %s", errUnknownCurrency, args[0].Text)
+				}
+				return new(big.Rat).Mul(args[1].Value, rate), nil
+			},
+		}),
+		calculator.WithFunction(calculator.Function{
+			Name:    "max",
+			MinArgs: 1,
+			MaxArgs: -1,
+			Apply: func(args ...*big.Rat) (*big.Rat, error) {
+				result := args[0]
+				for _, arg := range args[1:] {
+					if arg.Cmp(result) > 0 {
+						result = arg
+					}
+				}
+				return result, nil
+			},
+		}),
+		calculator.WithFunction(calculator.Function{
+			Name: "pi",
+			Apply: func(args ...*big.Rat) (*big.Rat, error) {
+				return big.NewRat(355, 113), nil
+			},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return c
+}
+
+func TestFunctionCalls(t *testing.T) {
+	c := functionCalculator(t)
+
+	tests := []struct {
+		expression string
+		expected   string
+	}{
+		{"tax(100)", "20"},
+		{"100 + tax(100)", "120"},
+		{"tax(50 + 50) x 2", "40"},
+		{"fx(\"EUR\", 10)", "11"},
+		{"fx(\"GBP\", tax(20)) - 1", "4"},
+		{"max(1, 7 / 2, -3)", "7/2"},
+		{"max(-1)", "-1"},
+		{"pi() x 113", "355"},
+		{"tax (5)", "1"},
+		{"fx(\"EUR €\", 1)", ""},
+	}
+	for _, test := range tests {
+		result, err := c.Calculate(test.expression)
+		if test.expected == "" {
+			if !errors.Is(err, errUnknownCurrency) {
+				t.Errorf("Calculate(%q) error = %v, want errUnknownCurrency", test.expression, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Calculate(%q) error = %v", test.expression, err)
+			continue
+		}
+		if got := calculator.FormatRational(result); got != test.expected {
+			t.Errorf("Calculate(%q) = %s, want %s", test.expression, got, test.expected)
+		}
+	}
+
+	program, err := c.Compile("tax(price) + fx(\"EUR\", price)")
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	result, err := program.Eval(calculator.Bindings{"price": big.NewRat(10, 1)})
+	if err != nil || calculator.FormatRational(result) != "13" {
+		t.Errorf("Eval() = %v, %v, want 13", result, err)
+	}
+}
+
+func TestFunctionErrors(t *testing.T) {
+	c := functionCalculator(t)
+
+	_, err := c.Calculate("1 + fx(\"JPY\", 5)")
+	var call calculator.CallError
+	if !errors.As(err, &call) || call.Name != "fx" || call.Position != 4 || call.Context == "" {
+		t.Errorf("Calculate() error = %#v, want CallError for fx at 4", err)
+	}
+	if !errors.Is(err, errUnknownCurrency) || !errors.Is(err, calculator.ErrCall) {
+		t.Errorf("Calculate() error = %v, want ErrCall wrapping errUnknownCurrency", err)
+	}
+
+	tests := []struct {
+		expression string
+		position   int
+		message    string
+	}{
+		{"tax(1, 2)", 0, "tax takes 1 argument, got 2"},
+		{"max()", 0, "max takes at least 1 argument, got 0"},
+		{"2 + vat(3)", 4, "Unknown function vat"},
+		{"tax(\"EUR\")", 4, "tax does not take text arguments"},
+		{"fx(\"EUR\" + 1, 2)", 3, "Text is only allowed as a function argument"},
+		{"tax(1 +)", 7, "Expected number"},
+		{"tax(1", 0, "Missing ) after arguments to tax"},
+		{"tax(", 0, "Missing ) after arguments to tax"},
+		{"fx(\"EUR, 1)", 0, "Missing ) after arguments to fx"},
+		{"tax(1) 2", 7, "Expected operator"},
+		{"tax(1) tax(2)", 7, "Expected operator"},
+		{"2 tax(1)", 2, "Expected operator"},
+		{"(1 + 2)", 0, "Invalid character '('"},
+	}
+	for _, test := range tests {
+		_, err := c.Calculate(test.expression)
+		source, ok := err.(calculator.SourceError)
+		if !ok || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("Calculate(%q) error = %v, want %q", test.expression, err, test.message)
+			continue
+		}
+		if start, _ := source.Span(); start != test.position {
+			t.Errorf("Calculate(%q) position = %d, want %d", test.expression, start, test.position)
+		}
+
+		// ValidateAll reports the same error first
+		var list calculator.ErrorList
+		if !errors.As(c.ValidateAll(test.expression), &list) || list[0].Error() != err.Error() {
+			t.Errorf("ValidateAll(%q) = %v, want %v first", test.expression, list, err)
+		}
+	}
+
+	err = c.ValidateAll("tax(1, 2) + vat(3)")
+	var list calculator.ErrorList
+	if !errors.As(err, &list) || len(list) != 2 {
+		t.Errorf("ValidateAll() = %v, want 2 errors", err)
+	}
+
+	// Calculators without functions keep rejecting parentheses
+	if _, err := calculator.Calculate("tax(1)"); !errors.Is(err, calculator.ErrInvalidCharacter) {
+		t.Errorf("Calculate(\"tax(1)\") error = %v, want ErrInvalidCharacter", err)
+	}
+}
+
+func TestInvalidFunctions(t *testing.T) {
+	apply := func(args ...*big.Rat) (*big.Rat, error) { return args[0], nil }
+	tests := []struct {
+		name string
+		fn   calculator.Function
+	}{
+		{"no name", calculator.Function{Apply: apply}},
+		{"leading digit", calculator.Function{Name: "2x", Apply: apply}},
+		{"punctuation", calculator.Function{Name: "a-b", Apply: apply}},
+		{"no implementation", calculator.Function{Name: "f"}},
+		{"two implementations", calculator.Function{Name: "f", Apply: apply, ApplyText: func(args ...calculator.Argument) (*big.Rat, error) { return nil, nil }}},
+		{"negative arguments", calculator.Function{Name: "f", MinArgs: -1, Apply: apply}},
+	}
+	for _, test := range tests {
+		if _, err := calculator.New(calculator.WithFunction(test.fn)); !errors.Is(err, calculator.ErrInvalidOption) {
+			t.Errorf("%s: New() error = %v, want ErrInvalidOption", test.name, err)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package unit
+
+import (
+	"errors"
+	"math/big"
+	"precise-calc/pkg/calculator"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCalculatorOperators(t *testing.T) {
+	// Subtraction binding tighter than multiplication, and right-associative
+	c, err := calculator.New(calculator.WithOperators(
+		calculator.AdditionOp,
+		calculator.Operator{Symbol: '-', Precedence: 3, Associativity: calculator.Right},
+		calculator.MultiplicationOp,
+		calculator.DivisionOp,
+	))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		expression string
+		expected   string
+	}{
+		{"2 x 5 - 3", "4"},
+		{"10 - 4 - 3", "9"},
+		{"1 + 2 x 3", "7"},
+	}
+	for _, test := range tests {
+		result, err := c.Calculate(test.expression)
+		if err != nil {
+			t.Errorf("Calculate(%q) error = %v", test.expression, err)
+			continue
+		}
+		if got := calculator.FormatRational(result); got != test.expected {
+			t.Errorf("Calculate(%q) = %s, want %s", test.expression, got, test.expected)
+		}
+	}
+
+	// The default calculator is unaffected
+	result, _ := calculator.Calculate("2 x 5 - 3")
+	if got := calculator.FormatRational(result); got != "7" {
+		t.Errorf("Calculate(\"2 x 5 - 3\") = %s, want 7", got)
+	}
+}
+
+func TestCalculatorDroppedOperator(t *testing.T) {
+	c, err := calculator.New(calculator.WithOperators(calculator.AdditionOp, calculator.SubtractionOp))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, err = c.Calculate("6 / 2")
+	var invalid calculator.InvalidCharacterError
+	if !errors.As(err, &invalid) || invalid.Character != '/' || invalid.Position != 2 {
+		t.Errorf("Calculate(\"6 / 2\") error = %#v, want InvalidCharacterError for '/'", err)
+	}
+}
+
+func TestCalculatorLiterals(t *testing.T) {
+	c, err := calculator.New(calculator.WithLiterals(calculator.Decimal, calculator.Mixed))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	result, err := c.Calculate("2 1/2 + 1")
+	if err != nil || calculator.FormatRational(result) != "7/2" {
+		t.Errorf("Calculate(\"2 1/2 + 1\") = %v, %v, want 7/2", result, err)
+	}
+
+	_, err = c.Calculate("1 + 0xFF")
+	var parseErr calculator.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Position != 4 || !strings.Contains(parseErr.Message, "Hexadecimal literals are not allowed") {
+		t.Errorf("Calculate(\"1 + 0xFF\") error = %v, want hexadecimal literal rejected at 4", err)
+	}
+	if err := c.Validate("0b101 x 2"); !errors.Is(err, calculator.ErrSyntax) {
+		t.Errorf("Validate(\"0b101 x 2\") = %v, want a syntax error", err)
+	}
+	if err := c.Validate("1.5 x 2"); err != nil {
+		t.Errorf("Validate(\"1.5 x 2\") = %v, want nil", err)
+	}
+}
+
+func TestCalculatorLimits(t *testing.T) {
+	c, err := calculator.New(calculator.WithLimits(calculator.Limits{MaxSteps: 1}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := c.Calculate("1 + 2 + 3"); !errors.Is(err, calculator.ErrStepLimit) {
+		t.Errorf("Calculate() error = %v, want ErrStepLimit", err)
+	}
+	if err := c.ValidateAll("1 + + 2"); err == nil {
+		t.Error("ValidateAll(\"1 + + 2\") = nil, want an error")
+	}
+}
+
+func TestCalculatorFormat(t *testing.T) {
+	value := big.NewRat(5, 2)
+	tests := []struct {
+		format   calculator.Format
+		expected string
+	}{
+		{calculator.Format{}, "5/2"},
+		{calculator.Format{Mixed: true}, "2 1/2"},
+		{calculator.Format{Radix: calculator.RadixOptions{Base: 2, Prefix: true}}, "0b10.1"},
+		{calculator.Format{Pattern: "0.00"}, "2.50"},
+	}
+	for _, test := range tests {
+		c, err := calculator.New(calculator.WithFormat(test.format))
+		if err != nil {
+			t.Errorf("New(%+v) error = %v", test.format, err)
+			continue
+		}
+		got, err := c.Format(value)
+		if err != nil || got != test.expected {
+			t.Errorf("Format() with %+v = %q, %v, want %q", test.format, got, err, test.expected)
+		}
+	}
+}
+
+func TestCalculatorInvalidOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		option   calculator.Option
+		sentinel error
+	}{
+		{"unknown operator", calculator.WithOperators(calculator.Operator{Symbol: '%', Precedence: 2}), calculator.ErrInvalidOption},
+		{"duplicate operator", calculator.WithOperators(calculator.AdditionOp, calculator.AdditionOp), calculator.ErrInvalidOption},
+		{"no literals", calculator.WithLiterals(), calculator.ErrInvalidOption},
+		{"negative limit", calculator.WithLimits(calculator.Limits{MaxDepth: -1}), calculator.ErrInvalidOption},
+		{"bad base", calculator.WithFormat(calculator.Format{Radix: calculator.RadixOptions{Base: 40}}), calculator.ErrInvalidBase},
+		{"bad pattern", calculator.WithFormat(calculator.Format{Pattern: "0.0.0"}), calculator.ErrInvalidPattern},
+	}
+	for _, test := range tests {
+		c, err := calculator.New(test.option)
+		if c != nil || !errors.Is(err, test.sentinel) || !errors.Is(err, calculator.ErrConfiguration) {
+			t.Errorf("%s: New() = %v, %v, want %v", test.name, c, err, test.sentinel)
+		}
+	}
+}
+
+func TestCalculatorConcurrentUse(t *testing.T) {
+	c, err := calculator.New(calculator.WithLimits(calculator.DefaultLimits), calculator.WithFormat(calculator.Format{Mixed: true}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				result, err := c.Calculate("7 / 2 + 0x1")
+				if err != nil {
+					t.Errorf("Calculate() error = %v", err)
+					return
+				}
+				if got, _ := c.Format(result); got != "4 1/2" {
+					t.Errorf("Format() = %q, want \"4 1/2\"", got)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
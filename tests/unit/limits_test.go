@@ -0,0 +1,133 @@
+package unit
+
+import (
+	"errors"
+	"precise-calc/pkg/calculator"
+	"strings"
+	"testing"
+)
+
+func TestLimitErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		limits     calculator.Limits
+		expression string
+		sentinel   error
+		code       calculator.ErrorCode
+		position   int
+	}{
+		{"length", calculator.Limits{MaxLength: 5}, "1 + 2 + 3", calculator.ErrInputTooLong, calculator.CodeInputTooLong, -1},
+		{"tokens", calculator.Limits{MaxTokens: 3}, "1 + 2 + 3", calculator.ErrTooManyTokens, calculator.CodeTooManyTokens, 6},
+		{"depth", calculator.Limits{MaxDepth: 2}, "1 + 2 x 3", calculator.ErrDepthLimit, calculator.CodeDepthLimit, 8},
+		{"literal bits", calculator.Limits{MaxBits: 8}, "1 + 0x1FF", calculator.ErrNumberTooLarge, calculator.CodeNumberTooLarge, 4},
+		{"result bits", calculator.Limits{MaxBits: 8}, "255 + 1", calculator.ErrNumberTooLarge, calculator.CodeNumberTooLarge, 4},
+		{"denominator bits", calculator.Limits{MaxBits: 8}, "1 / 3 / 3 / 3 / 3 / 3 / 3", calculator.ErrNumberTooLarge, calculator.CodeNumberTooLarge, 22},
+		{"steps", calculator.Limits{MaxSteps: 2}, "1 + 2 + 3 + 4", calculator.ErrStepLimit, calculator.CodeStepLimit, 10},
+	}
+
+	for _, test := range tests {
+		_, err := calculator.CalculateWithOptions(test.expression, calculator.Options{Limits: test.limits})
+		if !errors.Is(err, test.sentinel) || !errors.Is(err, calculator.ErrLimit) {
+			t.Errorf("%s: error = %v, want %v", test.name, err, test.sentinel)
+			continue
+		}
+		if calculator.CodeOf(err) != test.code {
+			t.Errorf("%s: code = %s, want %s", test.name, calculator.CodeOf(err), test.code)
+		}
+
+		position := -1
+		if source, ok := err.(calculator.SourceError); ok {
+			position, _ = source.Span()
+			if source.Snippet() == "" {
+				t.Errorf("%s: error has no snippet", test.name)
+			}
+		}
+		if position != test.position {
+			t.Errorf("%s: position = %d, want %d", test.name, position, test.position)
+		}
+	}
+}
+
+func TestLimitErrorTypes(t *testing.T) {
+	opts := calculator.Options{Limits: calculator.Limits{MaxBits: 8, MaxSteps: 1}}
+
+	_, err := calculator.CalculateWithOptions("200 x 2", opts)
+	var tooLarge calculator.NumberTooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.Bits != 9 || tooLarge.Limit != 8 {
+		t.Errorf("error = %#v, want NumberTooLargeError with 9 bits", err)
+	}
+
+	_, err = calculator.CalculateWithOptions("1 + 1 + 1", opts)
+	var steps calculator.StepLimitError
+	if !errors.As(err, &steps) || steps.Limit != 1 {
+		t.Errorf("error = %#v, want StepLimitError", err)
+	}
+}
+
+func TestLimitsAllowWithinBounds(t *testing.T) {
+	opts := calculator.Options{Limits: calculator.DefaultLimits}
+	for _, expression := range []string{"0.1 + 0.2", "1 / 3 / 7 x 0xFF", strings.Repeat("1 + ", 500) + "1"} {
+		if _, err := calculator.CalculateWithOptions(expression, opts); err != nil {
+			t.Errorf("CalculateWithOptions(%.20q) error: %v", expression, err)
+		}
+	}
+
+	// Chained divisions that would keep growing are stopped early
+	expression := "1" + strings.Repeat(" / 3", 999)
+	_, err := calculator.CalculateWithOptions(expression, calculator.Options{Limits: calculator.Limits{MaxBits: 1000}})
+	if !errors.Is(err, calculator.ErrNumberTooLarge) {
+		t.Errorf("chained division error = %v, want ErrNumberTooLarge", err)
+	}
+}
+
+func TestModeLimits(t *testing.T) {
+	c, err := calculator.New(calculator.WithLimits(calculator.Limits{MaxBits: 16, MaxSteps: 2}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	numeric, err := calculator.NewFixedDecimal(38, 2)
+	if err != nil {
+		t.Fatalf("NewFixedDecimal() error = %v", err)
+	}
+
+	modes := []struct {
+		name      string
+		calculate func(expression string) error
+	}{
+		{"decimal", func(expression string) error {
+			_, err := c.CalculateDecimal(expression, calculator.DivisionContext{Scale: 2})
+			return err
+		}},
+		{"significant", func(expression string) error {
+			_, err := c.CalculateSignificant(expression)
+			return err
+		}},
+		{"fixed", func(expression string) error {
+			_, err := c.CalculateFixed(expression, numeric)
+			return err
+		}},
+		{"context", func(expression string) error {
+			_, err := c.CalculateIn(expression, calculator.DefaultContext)
+			return err
+		}},
+	}
+	for _, mode := range modes {
+		if err := mode.calculate("1.5 + 2"); err != nil {
+			t.Errorf("%s: error = %v", mode.name, err)
+		}
+		if err := mode.calculate("1 + 2 + 3 + 4"); !errors.Is(err, calculator.ErrStepLimit) {
+			t.Errorf("%s: error = %v, want ErrStepLimit", mode.name, err)
+		}
+		if err := mode.calculate("300 x 300"); !errors.Is(err, calculator.ErrNumberTooLarge) {
+			t.Errorf("%s: error = %v, want ErrNumberTooLarge", mode.name, err)
+		}
+	}
+}
+
+func TestValidateAllChecksLimits(t *testing.T) {
+	opts := calculator.Options{Limits: calculator.Limits{MaxTokens: 3}}
+	err := calculator.ValidateAll("1 + 2 +", opts)
+	if !errors.Is(err, calculator.ErrTooManyTokens) || !errors.Is(err, calculator.ErrParse) {
+		t.Errorf("ValidateAll error = %v, want too many tokens and a parse error", err)
+	}
+}
@@ -0,0 +1,105 @@
+package unit
+
+import (
+	"errors"
+	"precise-calc/pkg/calculator"
+	"testing"
+)
+
+func TestLocate(t *testing.T) {
+	tests := []struct {
+		expression string
+		position   int
+		expected   calculator.Location
+	}{
+		{"1 + 2", 4, calculator.Location{Offset: 4, Rune: 4, Line: 1, Column: 5}},
+		{"1\u00a0+ 2", 4, calculator.Location{Offset: 5, Rune: 4, Line: 1, Column: 5}},
+		{"1 +\n2", 4, calculator.Location{Offset: 4, Rune: 4, Line: 2, Column: 1}},
+		{"1 +\n\u2003\u20032", 6, calculator.Location{Offset: 10, Rune: 6, Line: 2, Column: 3}},
+		{"1 +\n", 3, calculator.Location{Offset: 3, Rune: 3, Line: 1, Column: 4}},
+		{"1 +\n", 9, calculator.Location{Offset: 4, Rune: 4, Line: 2, Column: 1}},
+	}
+
+	for _, test := range tests {
+		result := calculator.Locate(test.expression, test.position)
+		if result != test.expected {
+			t.Errorf("Locate(%q, %d) = %+v, want %+v", test.expression, test.position, result, test.expected)
+		}
+	}
+}
+
+func TestTokenLocations(t *testing.T) {
+	// A no-break space is two bytes in UTF-8, and an em space three
+	expression := "1\u00a0+ 2\n\u2003x 0x1F"
+	tokens, err := calculator.Tokenize(expression)
+	if err != nil {
+		t.Fatalf("Tokenize error: %v", err)
+	}
+
+	expected := []calculator.Location{
+		{Offset: 0, Rune: 0, Line: 1, Column: 1},
+		{Offset: 3, Rune: 2, Line: 1, Column: 3},
+		{Offset: 5, Rune: 4, Line: 1, Column: 5},
+		{Offset: 10, Rune: 7, Line: 2, Column: 2},
+		{Offset: 12, Rune: 9, Line: 2, Column: 4},
+	}
+	if len(tokens) != len(expected) {
+		t.Fatalf("Tokenize returned %d tokens, want %d", len(tokens), len(expected))
+	}
+	for i, token := range tokens {
+		if token.Location != expected[i] {
+			t.Errorf("Token %q location = %+v, want %+v", token.Value, token.Location, expected[i])
+		}
+		if token.Position != token.Location.Rune {
+			t.Errorf("Token %q position %d differs from rune offset %d", token.Value, token.Position, token.Location.Rune)
+		}
+		if expression[token.Location.Offset:token.Location.Offset+len(token.Value)] != token.Value {
+			t.Errorf("Token %q not found at byte offset %d", token.Value, token.Location.Offset)
+		}
+	}
+}
+
+func TestInvalidCharacterRunePosition(t *testing.T) {
+	// Positions count runes even after the two-byte no-break space
+	for _, expression := range []string{"1\u00a0+ @", "1\u00a0+ \u00e9"} {
+		_, err := calculator.Tokenize(expression)
+		var invalid calculator.InvalidCharacterError
+		if !errors.As(err, &invalid) {
+			t.Errorf("Tokenize(%q) error = %v, want InvalidCharacterError", expression, err)
+			continue
+		}
+		if invalid.Position != 4 || invalid.Location.Offset != 5 || invalid.Location.Column != 5 {
+			t.Errorf("Tokenize(%q) = position %d, %+v; want position 4 at byte 5", expression, invalid.Position, invalid.Location)
+		}
+	}
+}
+
+func TestMultiLineErrors(t *testing.T) {
+	tests := []struct {
+		expression string
+		line       int
+		column     int
+		snippet    string
+	}{
+		{"1 + 2\n3 x + 4", 2, 1, "2 | 3 x + 4\n  | ^"},
+		{"1 +\n  2 / 0", 2, 5, "2 |   2 / 0\n  |     ^"},
+		{"1 +\n\t2 + @", 2, 6, "2 | \t2 + @\n  | \t    ^"},
+		{"1 +\n+ 2", 2, 1, "2 | + 2\n  | ^"},
+	}
+
+	for _, test := range tests {
+		_, err := calculator.Calculate(test.expression)
+		var source calculator.SourceError
+		if !errors.As(err, &source) {
+			t.Errorf("Calculate(%q) error = %v, want a SourceError", test.expression, err)
+			continue
+		}
+		loc := source.Where()
+		if loc.Line != test.line || loc.Column != test.column {
+			t.Errorf("Calculate(%q) at %s, want %d:%d", test.expression, loc, test.line, test.column)
+		}
+		if source.Snippet() != test.snippet {
+			t.Errorf("Calculate(%q) snippet = %q, want %q", test.expression, source.Snippet(), test.snippet)
+		}
+	}
+}
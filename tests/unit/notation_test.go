@@ -0,0 +1,56 @@
+package unit
+
+import (
+	"math/big"
+	"precise-calc/pkg/calculator"
+	"testing"
+)
+
+func TestFormatScientific(t *testing.T) {
+	tiny := new(big.Rat).SetFrac(big.NewInt(1234), new(big.Int).Exp(big.NewInt(10), big.NewInt(43), nil))
+
+	tests := []struct {
+		value    *big.Rat
+		digits   int
+		expected string
+	}{
+		{tiny, 4, "1.234e-40"},
+		{big.NewRat(12345, 1), 3, "1.23e+4"},
+		{big.NewRat(12355, 1), 4, "1.236e+4"},
+		{big.NewRat(-15, 10), 1, "-2e+0"},
+		{big.NewRat(999, 1), 2, "1.0e+3"},
+		{big.NewRat(1, 3), 5, "3.3333e-1"},
+		{big.NewRat(0, 1), 3, "0.00e+0"},
+		{big.NewRat(7, 1), 0, "7e+0"},
+	}
+
+	for _, test := range tests {
+		result := calculator.FormatScientific(test.value, test.digits)
+		if result != test.expected {
+			t.Errorf("FormatScientific(%s, %d) = %s, want %s", test.value, test.digits, result, test.expected)
+		}
+	}
+}
+
+func TestFormatEngineering(t *testing.T) {
+	tests := []struct {
+		value    *big.Rat
+		digits   int
+		expected string
+	}{
+		{big.NewRat(12345, 1), 3, "12.3e+3"},
+		{big.NewRat(123456, 1), 4, "123.5e+3"},
+		{big.NewRat(1, 1000), 2, "1.0e-3"},
+		{big.NewRat(1, 10000), 3, "100e-6"},
+		{big.NewRat(-47, 100000), 2, "-470e-6"},
+		{big.NewRat(12345, 1), 1, "10e+3"},
+		{big.NewRat(999999, 1), 3, "1.00e+6"},
+	}
+
+	for _, test := range tests {
+		result := calculator.FormatEngineering(test.value, test.digits)
+		if result != test.expected {
+			t.Errorf("FormatEngineering(%s, %d) = %s, want %s", test.value, test.digits, result, test.expected)
+		}
+	}
+}
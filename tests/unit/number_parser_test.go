@@ -70,3 +70,62 @@ func TestParseHexadecimalEdgeCases(t *testing.T) {
 		}
 	}
 }
+
+func TestParseBinary(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectError bool
+	}{
+		{"0b101", "5", false},
+		{"-0b11", "-3", false},
+		{"0B1111", "15", false},
+		{"0b", "", true},
+		{"0b102", "", true},
+		{"101", "", true},
+	}
+
+	for _, test := range tests {
+		result, err := calculator.ParseBinary(test.input)
+		if test.expectError {
+			if err == nil {
+				t.Errorf("ParseBinary(%s) expected error, got none", test.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseBinary(%s) unexpected error: %v", test.input, err)
+			continue
+		}
+		if formatted := calculator.FormatRational(result); formatted != test.expected {
+			t.Errorf("ParseBinary(%s) = %s, want %s", test.input, formatted, test.expected)
+		}
+	}
+}
+
+func TestParseNumberRecordsType(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected calculator.NumberType
+	}{
+		{"42", calculator.Decimal},
+		{"-0.5", calculator.Decimal},
+		{"0xFF", calculator.Hexadecimal},
+		{"-0XAB", calculator.Hexadecimal},
+		{"0b101", calculator.Binary},
+	}
+
+	for _, test := range tests {
+		number, err := calculator.ParseNumber(test.input)
+		if err != nil {
+			t.Errorf("ParseNumber(%s) error: %v", test.input, err)
+			continue
+		}
+		if number.Type != test.expected {
+			t.Errorf("ParseNumber(%s) type = %v, want %v", test.input, number.Type, test.expected)
+		}
+		if number.Original != test.input {
+			t.Errorf("ParseNumber(%s) original = %q", test.input, number.Original)
+		}
+	}
+}
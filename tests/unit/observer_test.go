@@ -0,0 +1,221 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"precise-calc/pkg/calculator"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// recordingObserver records every event as a line of text
+type recordingObserver struct {
+	mu          sync.Mutex
+	events      []string
+	evaluations []calculator.Evaluation
+}
+
+func (o *recordingObserver) record(format string, args ...interface{}) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, fmt.Sprintf(format, args...))
+}
+
+func (o *recordingObserver) Tokenized(expression string, tokens []calculator.Token, err error) {
+	o.record("tokenized %d %v", len(tokens), calculator.CodeOf(err))
+}
+
+func (o *recordingObserver) Parsed(expression string, postfix []calculator.Token, err error) {
+	o.record("parsed %d %v", len(postfix), calculator.CodeOf(err))
+}
+
+func (o *recordingObserver) Evaluated(evaluation calculator.Evaluation) {
+	o.record("evaluated %s", evaluation.Code)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.evaluations = append(o.evaluations, evaluation)
+}
+
+// operationObserver also records each operation
+type operationObserver struct {
+	recordingObserver
+}
+
+func (o *operationObserver) Operation(step calculator.Step) {
+	o.record("step %s", step)
+}
+
+func TestObserver(t *testing.T) {
+	observer := &recordingObserver{}
+	c, err := calculator.New(calculator.WithObserver(observer))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if _, err := c.Calculate("0x100 x 0x100 / 3"); err != nil {
+		t.Fatalf("Calculate() error = %v", err)
+	}
+	if _, err := c.Calculate("1 / 0"); err == nil {
+		t.Fatal("Calculate(\"1 / 0\") succeeded")
+	}
+	if _, err := c.Calculate("1 + + 2"); err == nil {
+		t.Fatal("Calculate(\"1 + + 2\") succeeded")
+	}
+	if _, err := c.Calculate("1 @ 2"); err == nil {
+		t.Fatal("Calculate(\"1 @ 2\") succeeded")
+	}
+
+	expected := []string{
+		"tokenized 5 ", "parsed 5 ", "evaluated ",
+		"tokenized 3 ", "parsed 3 ", "evaluated DIVISION_BY_ZERO",
+		"tokenized 4 ", "parsed 0 PARSE_ERROR", "evaluated PARSE_ERROR",
+		"tokenized 0 INVALID_CHARACTER", "evaluated INVALID_CHARACTER",
+	}
+	if !reflect.DeepEqual(observer.events, expected) {
+		t.Errorf("events = %q, want %q", observer.events, expected)
+	}
+
+	first := observer.evaluations[0]
+	if first.Expression != "0x100 x 0x100 / 3" || first.Tokens != 5 || first.ResultBits != 17 || first.Err != nil || first.Duration <= 0 {
+		t.Errorf("first evaluation = %+v", first)
+	}
+	second := observer.evaluations[1]
+	if !errors.Is(second.Err, calculator.ErrDivisionByZero) || second.Tokens != 3 || second.ResultBits != 0 {
+		t.Errorf("second evaluation = %+v", second)
+	}
+}
+
+func TestOperationObserver(t *testing.T) {
+	observer := &operationObserver{}
+	c, err := calculator.New(calculator.WithObserver(observer))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	program, err := c.Compile("price x qty - 0.5")
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if _, err := program.Eval(calculator.Bindings{"price": big.NewRat(5, 2), "qty": big.NewRat(3, 1)}); err != nil {
+		t.Fatalf("Eval() error = %v", err)
+	}
+	if _, err := program.Eval(calculator.Bindings{"price": big.NewRat(1, 1)}); err == nil {
+		t.Fatal("Eval() without qty succeeded")
+	}
+
+	expected := []string{
+		"tokenized 5 ", "parsed 5 ",
+		"step 2.5 x 3 = 7.5", "step 7.5 - 0.5 = 7", "evaluated ",
+		"evaluated UNBOUND_VARIABLE",
+	}
+	if !reflect.DeepEqual(observer.events, expected) {
+		t.Errorf("events = %q, want %q", observer.events, expected)
+	}
+}
+
+func TestObserverExplain(t *testing.T) {
+	observer := &operationObserver{}
+	c, err := calculator.New(calculator.WithObserver(observer))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	explanation, err := c.Explain("1 + 2 x 3")
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if _, err := c.Explain("1 / 0"); err == nil {
+		t.Fatal("Explain(\"1 / 0\") succeeded")
+	}
+
+	expected := []string{
+		"tokenized 5 ", "parsed 5 ", "step 2 x 3 = 6", "step 1 + 6 = 7", "evaluated ",
+		"tokenized 3 ", "parsed 3 ", "evaluated DIVISION_BY_ZERO",
+	}
+	if !reflect.DeepEqual(observer.events, expected) {
+		t.Errorf("events = %q, want %q", observer.events, expected)
+	}
+	if len(explanation.Steps) != 2 {
+		t.Errorf("Steps = %v, want 2 steps", explanation.Steps)
+	}
+}
+
+func TestObserverConcurrent(t *testing.T) {
+	observer := &operationObserver{}
+	c, err := calculator.New(calculator.WithObserver(observer))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if _, err := c.Calculate("1 + 2 x 3"); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(observer.evaluations) != 80 || len(observer.events) != 80*5 {
+		t.Errorf("got %d evaluations and %d events, want 80 and 400", len(observer.evaluations), len(observer.events))
+	}
+
+	// Removing the observer stops the events
+	quiet, err := calculator.New(calculator.WithObserver(observer), calculator.WithObserver(nil))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := quiet.Calculate("1 + 1"); err != nil || len(observer.evaluations) != 80 {
+		t.Errorf("Calculate() with the observer removed = %v, %d evaluations", err, len(observer.evaluations))
+	}
+}
+
+func TestObserverCanceled(t *testing.T) {
+	observer := &recordingObserver{}
+	c, err := calculator.New(calculator.WithObserver(observer))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	program, err := c.Compile("n + 1")
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	observer.events = nil
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.CalculateContext(ctx, "1 + 2"); !errors.Is(err, context.Canceled) {
+		t.Errorf("CalculateContext() error = %v, want context.Canceled", err)
+	}
+	if _, err := program.EvalContext(ctx, calculator.Bindings{"n": big.NewRat(1, 1)}); !errors.Is(err, context.Canceled) {
+		t.Errorf("EvalContext() error = %v, want context.Canceled", err)
+	}
+
+	expected := []string{"evaluated CANCELED", "evaluated CANCELED"}
+	if !reflect.DeepEqual(observer.events, expected) {
+		t.Errorf("events = %q, want %q", observer.events, expected)
+	}
+	if len(observer.evaluations) != 2 || !errors.Is(observer.evaluations[0].Err, context.Canceled) {
+		t.Errorf("evaluations = %+v", observer.evaluations)
+	}
+}
+
+func BenchmarkCalculateObserved(b *testing.B) {
+	c, err := calculator.New(calculator.WithObserver(&recordingObserver{}))
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < b.N; i++ {
+		if _, err := c.Calculate("12.75 x 40 - 0.5 x 40 + 9.99 / 4 + 0x10"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package unit
+
+import (
+	"errors"
+	"math/big"
+	"precise-calc/pkg/calculator"
+	"strings"
+	"testing"
+)
+
+// percentOf computes left percent of right, as in "15 %of 200"
+var percentOf = calculator.Operator{
+	Name:       "%of",
+	Precedence: 2,
+	Apply: func(operands ...*big.Rat) (*big.Rat, error) {
+		result := new(big.Rat).Mul(operands[0], operands[1])
+		return result.Quo(result, big.NewRat(100, 1)), nil
+	},
+}
+
+func TestCustomOperators(t *testing.T) {
+	c, err := calculator.New(
+		calculator.WithOperator(percentOf),
+		calculator.WithOperator(calculator.Operator{
+			Symbol:     '@',
+			Precedence: 3,
+			Apply: func(operands ...*big.Rat) (*big.Rat, error) {
+				return new(big.Rat).Mul(operands[0], operands[1]), nil
+			},
+		}),
+		calculator.WithOperator(calculator.Operator{
+			Name:       "neg",
+			Precedence: 4,
+			Arity:      1,
+			Apply: func(operands ...*big.Rat) (*big.Rat, error) {
+				return new(big.Rat).Neg(operands[0]), nil
+			},
+		}),
+		calculator.WithOperator(calculator.Operator{
+			Symbol:        '^',
+			Precedence:    5,
+			Associativity: calculator.Right,
+			Apply: func(operands ...*big.Rat) (*big.Rat, error) {
+				if !operands[1].IsInt() || operands[1].Sign() < 0 {
+					return nil, errors.New("exponent must be a whole number")
+				}
+				result := big.NewRat(1, 1)
+				for i := int64(0); i < operands[1].Num().Int64(); i++ {
+					result.Mul(result, operands[0])
+				}
+				return result, nil
+			},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		expression string
+		expected   string
+	}{
+		{"15 %of 200", "30"},
+		{"1 + 15 %of 200", "31"},
+		{"2 @ 3 + 1", "7"},
+		{"1 + 2 @ 3 x 2", "13"},
+		{"neg 3 + 5", "2"},
+		{"neg neg 3", "3"},
+		{"10 - neg 2", "12"},
+		{"2 ^ 3 ^ 2", "512"},
+		{"0x10 %of 50", "8"},
+	}
+	for _, test := range tests {
+		result, err := c.Calculate(test.expression)
+		if err != nil {
+			t.Errorf("Calculate(%q) error = %v", test.expression, err)
+			continue
+		}
+		if got := calculator.FormatRational(result); got != test.expected {
+			t.Errorf("Calculate(%q) = %s, want %s", test.expression, got, test.expected)
+		}
+	}
+
+	// Operator tokens carry their definitions through the package-level
+	// pipeline
+	tokens, err := c.Tokenize("neg 2 @ 5")
+	if err != nil {
+		t.Fatalf("Tokenize() error = %v", err)
+	}
+	postfix, err := calculator.InfixToPostfix(tokens)
+	if err != nil {
+		t.Fatalf("InfixToPostfix() error = %v", err)
+	}
+	result, err := calculator.EvaluatePostfix(postfix)
+	if err != nil || calculator.FormatRational(result) != "-10" {
+		t.Errorf("EvaluatePostfix() = %v, %v, want -10", result, err)
+	}
+
+	// Other calculators are unaffected
+	if _, err := calculator.Calculate("2 @ 3"); !errors.Is(err, calculator.ErrInvalidCharacter) {
+		t.Errorf("Calculate(\"2 @ 3\") error = %v, want ErrInvalidCharacter", err)
+	}
+}
+
+func TestCustomOperatorErrors(t *testing.T) {
+	failing := calculator.Operator{
+		Name:       "fails",
+		Precedence: 1,
+		Apply: func(operands ...*big.Rat) (*big.Rat, error) {
+			return nil, calculator.DivisionByZeroError{}
+		},
+	}
+	c, err := calculator.New(calculator.WithOperator(failing), calculator.WithOperator(percentOf))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, err = c.Calculate("1 + 2 fails 3")
+	var call calculator.CallError
+	if !errors.As(err, &call) || call.Name != "fails" || call.Position != 6 || call.End != 11 || call.Context == "" {
+		t.Errorf("Calculate() error = %#v, want CallError for fails at 6", err)
+	}
+	if !errors.Is(err, calculator.ErrCall) || !errors.Is(err, calculator.ErrDivisionByZero) || !errors.Is(err, calculator.ErrArithmetic) {
+		t.Errorf("Calculate() error = %v, want ErrCall wrapping ErrDivisionByZero", err)
+	}
+
+	tests := []struct {
+		expression string
+		message    string
+	}{
+		{"%of 5", "must start with a number"},
+		{"5 %of", "must end with a number"},
+		{"5 %off 2", "Invalid character"},
+	}
+	for _, test := range tests {
+		_, err := c.Calculate(test.expression)
+		if err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("Calculate(%q) error = %v, want %q", test.expression, err, test.message)
+		}
+	}
+}
+
+func TestOperatorsBesideLiterals(t *testing.T) {
+	c, err := calculator.New(calculator.WithOperator(calculator.Operator{
+		Name:       "mod",
+		Precedence: 2,
+		Apply: func(operands ...*big.Rat) (*big.Rat, error) {
+			return new(big.Rat).SetInt(new(big.Int).Mod(operands[0].Num(), operands[1].Num())), nil
+		},
+	}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		expression string
+		expected   string
+	}{
+		{"0xff mod 0x10", "15"},
+		{"0b101 mod 0b11", "2"},
+		{"0x1F mod 7", "3"},
+	}
+	for _, test := range tests {
+		result, err := c.Calculate(test.expression)
+		if err != nil {
+			t.Errorf("Calculate(%q) error = %v", test.expression, err)
+			continue
+		}
+		if got := calculator.FormatRational(result); got != test.expected {
+			t.Errorf("Calculate(%q) = %s, want %s", test.expression, got, test.expected)
+		}
+	}
+}
+
+func TestInvalidOperators(t *testing.T) {
+	apply := func(operands ...*big.Rat) (*big.Rat, error) { return operands[0], nil }
+	tests := []struct {
+		name string
+		op   calculator.Operator
+	}{
+		{"no apply", calculator.Operator{Symbol: '%', Precedence: 1}},
+		{"no spelling", calculator.Operator{Precedence: 1, Apply: apply}},
+		{"symbol and name", calculator.Operator{Symbol: '%', Name: "pct", Apply: apply}},
+		{"hex prefix", calculator.Operator{Name: "xor", Apply: apply}},
+		{"upper hex prefix", calculator.Operator{Symbol: 'X', Apply: apply}},
+		{"binary prefix", calculator.Operator{Name: "by", Apply: apply}},
+		{"binary prefix letter", calculator.Operator{Symbol: 'b', Apply: apply}},
+		{"hex digit", calculator.Operator{Symbol: 'e', Apply: apply}},
+		{"hex digit pair", calculator.Operator{Name: "ff", Apply: apply}},
+		{"hex word", calculator.Operator{Name: "add", Apply: apply}},
+		{"leading digit", calculator.Operator{Name: "2x", Apply: apply}},
+		{"negative literal", calculator.Operator{Name: "->", Apply: apply}},
+		{"whitespace", calculator.Operator{Name: "of\tx", Apply: apply}},
+		{"arity", calculator.Operator{Symbol: '~', Arity: 3, Apply: apply}},
+		{"unary builtin", calculator.Operator{Symbol: '-', Arity: 1}},
+	}
+	for _, test := range tests {
+		if _, err := calculator.New(calculator.WithOperator(test.op)); !errors.Is(err, calculator.ErrInvalidOption) {
+			t.Errorf("%s: New() error = %v, want ErrInvalidOption", test.name, err)
+		}
+	}
+}
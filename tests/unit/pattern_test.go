@@ -0,0 +1,103 @@
+package unit
+
+import (
+	"math/big"
+	"precise-calc/pkg/calculator"
+	"testing"
+)
+
+func TestFormatPattern(t *testing.T) {
+	enUS, _ := calculator.LookupLocale("en-US")
+
+	tests := []struct {
+		value    *big.Rat
+		pattern  string
+		expected string
+	}{
+		{big.NewRat(123456789, 100), "#,##0.00", "1,234,567.89"},
+		{big.NewRat(-123450, 100), "#,##0.00;(#,##0.00)", "(1,234.50)"},
+		{big.NewRat(5, 1000), "#,##0.00", "0.01"},
+		{big.NewRat(-1, 1000), "#,##0.00", "0.00"},
+		{big.NewRat(0, 1), "#,##0.00;(#,##0.00);'zero'", "zero"},
+		{big.NewRat(1, 2), "#.##", ".5"},
+		{big.NewRat(0, 1), "#", "0"},
+		{big.NewRat(7, 1), "000", "007"},
+		{big.NewRat(1, 3), "0.0##", "0.333"},
+		{big.NewRat(1, 2), "0.0##", "0.5"},
+		{big.NewRat(1, 8), "0.0%", "12.5%"},
+		{big.NewRat(-42, 1), "$#,##0", "-$42"},
+		{big.NewRat(42, 1), "0' units'", "42 units"},
+		{big.NewRat(123456789, 100), "#,##,##0.00", "12,34,567.89"},
+		{big.NewRat(25, 10), "0", "3"},
+		{big.NewRat(-25, 10), "0", "-3"},
+	}
+
+	for _, test := range tests {
+		result, err := calculator.FormatPattern(test.value, test.pattern, enUS)
+		if err != nil {
+			t.Errorf("FormatPattern(%s, %q) error: %v", test.value, test.pattern, err)
+			continue
+		}
+		if result != test.expected {
+			t.Errorf("FormatPattern(%s, %q) = %q, want %q", test.value, test.pattern, result, test.expected)
+		}
+	}
+}
+
+func TestFormatPatternInvalid(t *testing.T) {
+	patterns := []string{"", "abc", "0.0.0", "0.#0", "#,", "0 0", "'0", "0;0;0;0"}
+
+	for _, pattern := range patterns {
+		_, err := calculator.ParsePattern(pattern)
+		if _, ok := err.(calculator.InvalidPatternError); !ok {
+			t.Errorf("ParsePattern(%q) expected InvalidPatternError, got %v", pattern, err)
+		}
+	}
+}
+
+func TestFormatLocale(t *testing.T) {
+	value := big.NewRat(123456789, 100)
+
+	tests := []struct {
+		locale   string
+		expected string
+	}{
+		{"en-US", "1,234,567.89"},
+		{"de-DE", "1.234.567,89"},
+		{"en-IN", "12,34,567.89"},
+		{"fr-FR", "1 234 567,89"},
+		{"de-CH", "1’234’567.89"},
+	}
+
+	for _, test := range tests {
+		loc, ok := calculator.LookupLocale(test.locale)
+		if !ok {
+			t.Errorf("LookupLocale(%s) not found", test.locale)
+			continue
+		}
+		result, err := calculator.FormatLocale(value, loc)
+		if err != nil {
+			t.Errorf("FormatLocale(%s) error: %v", test.locale, err)
+			continue
+		}
+		if result != test.expected {
+			t.Errorf("FormatLocale(%s) = %q, want %q", test.locale, result, test.expected)
+		}
+	}
+
+	if _, ok := calculator.LookupLocale("xx-XX"); ok {
+		t.Errorf("LookupLocale(xx-XX) unexpectedly found")
+	}
+}
+
+func TestFormatLocaleWithPattern(t *testing.T) {
+	deDE, _ := calculator.LookupLocale("de-DE")
+
+	result, err := calculator.FormatPattern(big.NewRat(-123450, 100), "#,##0.00;(#,##0.00)", deDE)
+	if err != nil {
+		t.Fatalf("FormatPattern error: %v", err)
+	}
+	if result != "(1.234,50)" {
+		t.Errorf("FormatPattern with de-DE = %q, want (1.234,50)", result)
+	}
+}
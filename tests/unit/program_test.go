@@ -0,0 +1,134 @@
+package unit
+
+import (
+	"errors"
+	"math/big"
+	"precise-calc/pkg/calculator"
+	"reflect"
+	"testing"
+)
+
+func TestCompileEval(t *testing.T) {
+	tests := []struct {
+		expression string
+		bindings   calculator.Bindings
+		expected   string
+	}{
+		{"price x qty + shipping", calculator.Bindings{"price": big.NewRat(3, 2), "qty": big.NewRat(4, 1), "shipping": big.NewRat(5, 1)}, "11"},
+		{"2xrate", calculator.Bindings{"rate": big.NewRat(7, 1)}, "14"},
+		{"2x3 + x", calculator.Bindings{"x": big.NewRat(1, 3)}, "19/3"},
+		{"x x x", calculator.Bindings{"x": big.NewRat(-2, 1)}, "4"},
+		{"total / 0x10 - _fee2", calculator.Bindings{"total": big.NewRat(8, 1), "_fee2": big.NewRat(1, 4)}, "1/4"},
+		{"1 + 2", nil, "3"},
+	}
+
+	for _, test := range tests {
+		program, err := calculator.Compile(test.expression)
+		if err != nil {
+			t.Errorf("Compile(%q) error = %v", test.expression, err)
+			continue
+		}
+		result, err := program.Eval(test.bindings)
+		if err != nil {
+			t.Errorf("Eval(%q) error = %v", test.expression, err)
+			continue
+		}
+		if got := calculator.FormatRational(result); got != test.expected {
+			t.Errorf("Eval(%q) = %s, want %s", test.expression, got, test.expected)
+		}
+	}
+}
+
+func TestProgramReuse(t *testing.T) {
+	program, err := calculator.Compile("amount x rate")
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if got := program.Variables(); !reflect.DeepEqual(got, []string{"amount", "rate"}) {
+		t.Errorf("Variables() = %v", got)
+	}
+
+	amount := big.NewRat(10, 1)
+	for i := int64(1); i <= 3; i++ {
+		result, err := program.Eval(calculator.Bindings{"amount": amount, "rate": big.NewRat(i, 4)})
+		if err != nil || result.Cmp(big.NewRat(10*i, 4)) != 0 {
+			t.Errorf("Eval(rate=%d/4) = %v, %v", i, result, err)
+		}
+	}
+	if amount.Cmp(big.NewRat(10, 1)) != 0 {
+		t.Errorf("Eval modified a binding: amount = %v", amount)
+	}
+}
+
+func TestProgramErrors(t *testing.T) {
+	program, err := calculator.Compile("base + bonus / days")
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	_, err = program.Eval(calculator.Bindings{"base": big.NewRat(1, 1), "days": big.NewRat(1, 1)})
+	var unbound calculator.UnboundVariableError
+	if !errors.As(err, &unbound) || unbound.Name != "bonus" || unbound.Position != 7 || unbound.Context == "" {
+		t.Errorf("Eval() error = %#v, want UnboundVariableError for bonus at 7", err)
+	}
+	if !errors.Is(err, calculator.ErrUnboundVariable) || calculator.CodeOf(err) != calculator.CodeUnboundVariable {
+		t.Errorf("Eval() error = %v, want ErrUnboundVariable", err)
+	}
+
+	_, err = program.Eval(calculator.Bindings{"base": big.NewRat(1, 1), "bonus": big.NewRat(1, 1), "days": new(big.Rat)})
+	var divErr calculator.DivisionByZeroError
+	if !errors.As(err, &divErr) || divErr.Position != 13 {
+		t.Errorf("Eval() error = %#v, want DivisionByZeroError at 13", err)
+	}
+
+	if _, err := calculator.Compile("rate qty"); !errors.Is(err, calculator.ErrSyntax) {
+		t.Errorf("Compile(\"rate qty\") error = %v, want a syntax error", err)
+	}
+	if _, err := calculator.Calculate("2 x rate"); !errors.Is(err, calculator.ErrInvalidCharacter) {
+		t.Errorf("Calculate(\"2 x rate\") error = %v, want ErrInvalidCharacter", err)
+	}
+}
+
+func TestProgramLimits(t *testing.T) {
+	c, err := calculator.New(calculator.WithLimits(calculator.Limits{MaxBits: 8}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	program, err := c.Compile("n + 1")
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if _, err := program.Eval(calculator.Bindings{"n": big.NewRat(1000, 1)}); !errors.Is(err, calculator.ErrNumberTooLarge) {
+		t.Errorf("Eval() error = %v, want ErrNumberTooLarge", err)
+	}
+}
+
+const pricingFormula = "unit x quantity - discount x quantity + shipping / 4 + 0x10"
+
+func BenchmarkCalculatePricing(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := calculator.Calculate("12.75 x 40 - 0.5 x 40 + 9.99 / 4 + 0x10"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProgramEvalPricing(b *testing.B) {
+	program, err := calculator.Compile(pricingFormula)
+	if err != nil {
+		b.Fatal(err)
+	}
+	bindings := calculator.Bindings{
+		"unit":     big.NewRat(1275, 100),
+		"quantity": big.NewRat(40, 1),
+		"discount": big.NewRat(1, 2),
+		"shipping": big.NewRat(999, 100),
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := program.Eval(bindings); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package unit
+
+import (
+	"math/big"
+	"precise-calc/pkg/calculator"
+	"testing"
+)
+
+func TestFormatRadix(t *testing.T) {
+	tests := []struct {
+		value    *big.Rat
+		base     int
+		expected string
+	}{
+		{big.NewRat(4080, 1), 16, "0xff0"},
+		{big.NewRat(-255, 1), 16, "-0xff"},
+		{big.NewRat(5, 1), 2, "0b101"},
+		{big.NewRat(8, 1), 8, "0o10"},
+		{big.NewRat(0, 1), 16, "0x0"},
+		{big.NewRat(35, 1), 36, "z"},
+		{big.NewRat(1, 2), 16, "0x0.8"},
+		{big.NewRat(1, 3), 10, "0.(3)"},
+		{big.NewRat(1, 3), 16, "0x0.(5)"},
+		{big.NewRat(1, 10), 16, "0x0.1(9)"},
+		{big.NewRat(-7, 6), 10, "-1.1(6)"},
+		{big.NewRat(1, 7), 10, "0.(142857)"},
+	}
+
+	for _, test := range tests {
+		result, err := calculator.FormatRadix(test.value, test.base)
+		if err != nil {
+			t.Errorf("FormatRadix(%s, %d) error: %v", test.value, test.base, err)
+			continue
+		}
+		if result != test.expected {
+			t.Errorf("FormatRadix(%s, %d) = %s, want %s", test.value, test.base, result, test.expected)
+		}
+	}
+}
+
+func TestFormatRadixOptions(t *testing.T) {
+	value := big.NewRat(4080, 1)
+
+	result, err := calculator.FormatRadixWithOptions(value, calculator.RadixOptions{Base: 16, Prefix: true, Uppercase: true})
+	if err != nil {
+		t.Fatalf("FormatRadixWithOptions error: %v", err)
+	}
+	if result != "0xFF0" {
+		t.Errorf("uppercase hex = %s, want 0xFF0", result)
+	}
+
+	result, err = calculator.FormatRadixWithOptions(big.NewRat(1, 3), calculator.RadixOptions{Base: 10, MaxFractionDigits: 0})
+	if err != nil {
+		t.Fatalf("FormatRadixWithOptions error: %v", err)
+	}
+	if result != "0.(3)" {
+		t.Errorf("default digit limit = %s, want 0.(3)", result)
+	}
+
+	// A period longer than the limit is truncated rather than expanded
+	result, err = calculator.FormatRadixWithOptions(big.NewRat(1, 7), calculator.RadixOptions{Base: 10, MaxFractionDigits: 3})
+	if err != nil {
+		t.Fatalf("FormatRadixWithOptions error: %v", err)
+	}
+	if result != "0.142..." {
+		t.Errorf("truncated expansion = %s, want 0.142...", result)
+	}
+}
+
+func TestFormatRadixInvalidBase(t *testing.T) {
+	for _, base := range []int{-1, 0, 1, 37} {
+		_, err := calculator.FormatRadix(big.NewRat(1, 1), base)
+		if _, ok := err.(calculator.InvalidBaseError); !ok {
+			t.Errorf("FormatRadix base %d: expected InvalidBaseError, got %v", base, err)
+		}
+	}
+}
+
+func TestAutoBase(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   int
+	}{
+		{"0x1000 - 0x10", 16},
+		{"0b101 + 0b1", 2},
+		{"0xFF + 1", 10},
+		{"0xFF + 0b1", 10},
+		{"0xF / 0x2", 10},
+		{"5 + 3", 10},
+	}
+
+	for _, test := range tests {
+		tokens, err := calculator.Tokenize(test.expression)
+		if err != nil {
+			t.Errorf("Tokenize(%s) error: %v", test.expression, err)
+			continue
+		}
+		result, err := calculator.Calculate(test.expression)
+		if err != nil {
+			t.Errorf("Calculate(%s) error: %v", test.expression, err)
+			continue
+		}
+		if base := calculator.AutoBase(result, tokens); base != test.expected {
+			t.Errorf("AutoBase(%s) = %d, want %d", test.expression, base, test.expected)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package unit
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"precise-calc/pkg/calculator"
+	"testing"
+)
+
+var (
+	_ fmt.Formatter            = calculator.Rational{}
+	_ json.Marshaler           = calculator.Rational{}
+	_ json.Unmarshaler         = (*calculator.Rational)(nil)
+	_ encoding.TextMarshaler   = calculator.Rational{}
+	_ encoding.TextUnmarshaler = (*calculator.Rational)(nil)
+	_ sql.Scanner              = (*calculator.Rational)(nil)
+	_ driver.Valuer            = calculator.Rational{}
+)
+
+// mustRational parses s or fails the test
+func mustRational(t *testing.T, s string) calculator.Rational {
+	t.Helper()
+	r, err := calculator.ParseRational(s)
+	if err != nil {
+		t.Fatalf("ParseRational(%q) error = %v", s, err)
+	}
+	return r
+}
+
+func TestRationalArithmetic(t *testing.T) {
+	a, b := mustRational(t, "0.1"), mustRational(t, "0.2")
+	if got := a.Add(b).String(); got != "0.3" {
+		t.Errorf("0.1 + 0.2 = %s, want 0.3", got)
+	}
+	if got := a.Sub(b).String(); got != "-0.1" {
+		t.Errorf("0.1 - 0.2 = %s, want -0.1", got)
+	}
+	if got := a.Mul(b).String(); got != "0.02" {
+		t.Errorf("0.1 x 0.2 = %s, want 0.02", got)
+	}
+	third, err := calculator.RationalFromInt(1).Quo(calculator.RationalFromInt(3))
+	if err != nil || third.String() != "1/3" {
+		t.Errorf("1 / 3 = %v, %v, want 1/3", third, err)
+	}
+	if _, err := a.Quo(calculator.Rational{}); !errors.Is(err, calculator.ErrDivisionByZero) {
+		t.Errorf("Quo(0) error = %v, want ErrDivisionByZero", err)
+	}
+	if !third.Add(third).Add(third).Equal(calculator.RationalFromInt(1)) {
+		t.Error("1/3 + 1/3 + 1/3 != 1")
+	}
+	if a.Cmp(b) != -1 || a.Neg().Abs().Cmp(a) != 0 || a.Neg().Sign() != -1 || (calculator.Rational{}).Sign() != 0 {
+		t.Error("Cmp, Neg, Abs or Sign gave the wrong result")
+	}
+
+	// Values are immutable: neither operands nor the source big.Rat change
+	source := big.NewRat(5, 2)
+	x := calculator.NewRational(source)
+	source.SetInt64(9)
+	x.Rat().SetInt64(7)
+	_ = x.Add(x)
+	if x.String() != "2.5" {
+		t.Errorf("x = %s after mutation attempts, want 2.5", x)
+	}
+}
+
+func TestParseRational(t *testing.T) {
+	tests := map[string]string{
+		"42":     "42",
+		"-0.125": "-0.125",
+		"0xFF":   "255",
+		"2 1/4":  "2.25",
+		"1/3":    "1/3",
+		"1.5e-3": "0.0015",
+		" 7 ":    "7",
+	}
+	for input, expected := range tests {
+		if got := mustRational(t, input).String(); got != expected {
+			t.Errorf("ParseRational(%q) = %s, want %s", input, got, expected)
+		}
+	}
+	if _, err := calculator.ParseRational("abc"); err == nil {
+		t.Error("ParseRational(\"abc\") succeeded")
+	}
+}
+
+func TestRationalFormat(t *testing.T) {
+	third, _ := calculator.RationalFromFrac(1, 3)
+	price := mustRational(t, "1234.5")
+	tests := []struct {
+		format   string
+		value    calculator.Rational
+		expected string
+	}{
+		{"%v", price, "1234.5"},
+		{"%v", third, "1/3"},
+		{"%s", calculator.Rational{}, "0"},
+		{"%.2f", price, "1234.50"},
+		{"%.2f", third, "0.33"},
+		{"%.0f", mustRational(t, "2.5"), "3"},
+		{"%f", price, "1234.5"},
+		{"%f", third, "0.333333"},
+		{"%x", calculator.RationalFromInt(255), "ff"},
+		{"%#X", calculator.RationalFromInt(255), "0xFF"},
+		{"%b", calculator.RationalFromInt(5), "101"},
+		{"%d", calculator.RationalFromInt(-12), "-12"},
+		{"%d", third, "%!d(calculator.Rational=1/3)"},
+		{"%.3e", price, "1.235e+03"},
+		{"%e", mustRational(t, "-2.345"), "-2.345000e+00"},
+		{"%.0E", mustRational(t, "0.00012"), "1E-04"},
+		{"%.2e", mustRational(t, "1e120"), "1.00e+120"},
+		{"%+v", price, "+1234.5"},
+		{"%10.2f|", price, "   1234.50|"},
+		{"%-8v|", third, "1/3     |"},
+		{"%08.2f", mustRational(t, "-3.5"), "-0003.50"},
+		{"%q", price, "%!q(calculator.Rational=1234.5)"},
+	}
+	for _, test := range tests {
+		if got := fmt.Sprintf(test.format, test.value); got != test.expected {
+			t.Errorf("Sprintf(%q, %s) = %q, want %q", test.format, test.value, got, test.expected)
+		}
+	}
+}
+
+func TestRationalJSON(t *testing.T) {
+	type invoice struct {
+		Total calculator.Rational  `json:"total"`
+		Share calculator.Rational  `json:"share"`
+		Tax   *calculator.Rational `json:"tax,omitempty"`
+	}
+
+	third, _ := calculator.RationalFromFrac(1, 3)
+	data, err := json.Marshal(invoice{Total: mustRational(t, "0.1"), Share: third})
+	if err != nil || string(data) != `{"total":"0.1","share":"1/3"}` {
+		t.Errorf("Marshal() = %s, %v", data, err)
+	}
+
+	var decoded invoice
+	if err := json.Unmarshal(data, &decoded); err != nil || !decoded.Share.Equal(third) {
+		t.Errorf("Unmarshal(%s) = %+v, %v", data, decoded, err)
+	}
+
+	// JSON numbers are read exactly, beyond float64 precision
+	input := `{"total": 0.10000000000000000001, "share": 1e-20, "tax": null}`
+	if err := json.Unmarshal([]byte(input), &decoded); err != nil {
+		t.Fatalf("Unmarshal(%s) error = %v", input, err)
+	}
+	if decoded.Total.String() != "0.10000000000000000001" || decoded.Share.String() != "0.00000000000000000001" || decoded.Tax != nil {
+		t.Errorf("Unmarshal(%s) = %+v", input, decoded)
+	}
+
+	for _, bad := range []string{`{"total": "ten"}`, `{"total": true}`} {
+		if err := json.Unmarshal([]byte(bad), &decoded); err == nil {
+			t.Errorf("Unmarshal(%s) succeeded", bad)
+		}
+	}
+}
+
+func TestRationalText(t *testing.T) {
+	var r calculator.Rational
+	if err := r.UnmarshalText([]byte("-7/4")); err != nil {
+		t.Fatalf("UnmarshalText() error = %v", err)
+	}
+	if text, _ := r.MarshalText(); string(text) != "-1.75" {
+		t.Errorf("MarshalText() = %s, want -1.75", text)
+	}
+	if err := r.UnmarshalText([]byte("1..2")); err == nil {
+		t.Error("UnmarshalText(\"1..2\") succeeded")
+	}
+}
+
+func TestRationalSQL(t *testing.T) {
+	tests := []struct {
+		src      any
+		expected string
+	}{
+		{"19.99", "19.99"},
+		{[]byte("-0.005"), "-0.005"},
+		{int64(42), "42"},
+		{0.1, "0.1"},
+	}
+	for _, test := range tests {
+		var r calculator.Rational
+		if err := r.Scan(test.src); err != nil || r.String() != test.expected {
+			t.Errorf("Scan(%#v) = %s, %v, want %s", test.src, r, err, test.expected)
+		}
+	}
+
+	var r calculator.Rational
+	for _, bad := range []any{nil, true, "price"} {
+		if err := r.Scan(bad); err == nil {
+			t.Errorf("Scan(%#v) succeeded", bad)
+		}
+	}
+
+	var nullable sql.Null[calculator.Rational]
+	if err := nullable.Scan(nil); err != nil || nullable.Valid {
+		t.Errorf("sql.Null Scan(nil) = %+v, %v", nullable, err)
+	}
+
+	value, err := mustRational(t, "12.50").Value()
+	if err != nil || value != "12.5" {
+		t.Errorf("Value() = %#v, %v, want \"12.5\"", value, err)
+	}
+	third, _ := calculator.RationalFromFrac(1, 3)
+	if value, err := third.Value(); err == nil {
+		t.Errorf("Value() of 1/3 = %#v, want an error", value)
+	}
+}
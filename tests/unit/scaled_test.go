@@ -0,0 +1,113 @@
+package unit
+
+import (
+	"math/big"
+	"precise-calc/pkg/calculator"
+	"testing"
+)
+
+func TestCalculateDecimalScale(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   string
+		scale      int
+	}{
+		{"1.10 + 2.20", "3.30", 2},
+		{"1.1 + 2.20", "3.30", 2},
+		{"5.00 - 5", "0.00", 2},
+		{"1.5 x 2.25", "3.375", 3},
+		{"2.0 x 3.0", "6.00", 2},
+		{"10.00 / 4", "2.50", 2},
+		{"1.0 / 8", "0.125", 3},
+		{"10 / 0.5", "20", 0},
+		{"0xFF + 0.50", "255.50", 2},
+	}
+
+	for _, test := range tests {
+		result, err := calculator.CalculateDecimal(test.expression, calculator.DivisionContext{})
+		if err != nil {
+			t.Errorf("CalculateDecimal(%s) error: %v", test.expression, err)
+			continue
+		}
+		if result.String() != test.expected || result.Scale != test.scale {
+			t.Errorf("CalculateDecimal(%s) = %s (scale %d), want %s (scale %d)",
+				test.expression, result, result.Scale, test.expected, test.scale)
+		}
+	}
+}
+
+func TestCalculateDecimalDivisionContext(t *testing.T) {
+	tests := []struct {
+		expression string
+		ctx        calculator.DivisionContext
+		expected   string
+	}{
+		{"2 / 3", calculator.DivisionContext{Scale: 4}, "0.6667"},
+		{"10.00 / 3", calculator.DivisionContext{Rounding: calculator.RoundHalfEven}, "3.33"},
+		{"2 / 3", calculator.DivisionContext{Rounding: calculator.RoundUp}, "1"},
+		{"2 / 3", calculator.DivisionContext{Set: true}, "1"},
+		{"10.00 / 3", calculator.DivisionContext{Set: true}, "3.33"},
+		{"2 / 3", calculator.DivisionContext{Scale: 4, Rounding: calculator.RoundDown}, "0.6666"},
+		{"-2 / 3", calculator.DivisionContext{Scale: 2, Rounding: calculator.RoundFloor}, "-0.67"},
+		{"-2 / 3", calculator.DivisionContext{Scale: 2, Rounding: calculator.RoundCeiling}, "-0.66"},
+		{"1 / 8", calculator.DivisionContext{Scale: 2, Rounding: calculator.RoundUnnecessary}, "0.125"},
+	}
+
+	for _, test := range tests {
+		result, err := calculator.CalculateDecimal(test.expression, test.ctx)
+		if err != nil {
+			t.Errorf("CalculateDecimal(%s, %+v) error: %v", test.expression, test.ctx, err)
+			continue
+		}
+		if result.String() != test.expected {
+			t.Errorf("CalculateDecimal(%s, %+v) = %s, want %s", test.expression, test.ctx, result, test.expected)
+		}
+	}
+
+	_, err := calculator.CalculateDecimal("2 / 3", calculator.DivisionContext{Rounding: calculator.RoundUnnecessary})
+	if e, ok := err.(calculator.RoundingRequiredError); !ok || e.Position != 2 {
+		t.Errorf("Expected RoundingRequiredError at position 2, got %v", err)
+	}
+
+	// Without a context an inexact quotient is not silently rounded
+	_, err = calculator.CalculateDecimal("1 + 10.00 / 3", calculator.DivisionContext{})
+	if e, ok := err.(calculator.RoundingRequiredError); !ok || e.Position != 10 {
+		t.Errorf("Expected RoundingRequiredError at position 10 for the zero context, got %v", err)
+	}
+}
+
+func TestRoundingModes(t *testing.T) {
+	tests := []struct {
+		value    *big.Rat
+		mode     calculator.RoundingMode
+		expected string
+	}{
+		{big.NewRat(25, 10), calculator.RoundHalfUp, "3"},
+		{big.NewRat(25, 10), calculator.RoundHalfEven, "2"},
+		{big.NewRat(35, 10), calculator.RoundHalfEven, "4"},
+		{big.NewRat(25, 10), calculator.RoundHalfDown, "2"},
+		{big.NewRat(-25, 10), calculator.RoundHalfUp, "-3"},
+		{big.NewRat(21, 10), calculator.RoundUp, "3"},
+		{big.NewRat(29, 10), calculator.RoundDown, "2"},
+		{big.NewRat(-21, 10), calculator.RoundCeiling, "-2"},
+		{big.NewRat(-21, 10), calculator.RoundFloor, "-3"},
+	}
+
+	for _, test := range tests {
+		result, exact := calculator.NewScaledDecimal(test.value, 0, test.mode)
+		if result.String() != test.expected || exact {
+			t.Errorf("NewScaledDecimal(%s, 0, %s) = %s (exact %v), want %s (inexact)",
+				test.value, test.mode, result, exact, test.expected)
+		}
+	}
+
+	for _, name := range []string{"half-up", "half-even", "half-down", "up", "down", "ceiling", "floor", "unnecessary"} {
+		mode, err := calculator.ParseRoundingMode(name)
+		if err != nil || mode.String() != name {
+			t.Errorf("ParseRoundingMode(%s) = %s, %v", name, mode, err)
+		}
+	}
+	if _, err := calculator.ParseRoundingMode("sideways"); err == nil {
+		t.Errorf("ParseRoundingMode(sideways) expected error")
+	}
+}
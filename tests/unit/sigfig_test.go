@@ -0,0 +1,101 @@
+package unit
+
+import (
+	"math/big"
+	"precise-calc/pkg/calculator"
+	"testing"
+)
+
+func TestFormatSignificant(t *testing.T) {
+	tests := []struct {
+		value    *big.Rat
+		figures  int
+		expected string
+	}{
+		{big.NewRat(12345, 10), 3, "1230"},
+		{big.NewRat(12345, 1000000), 3, "0.0123"},
+		{big.NewRat(2, 3), 4, "0.6667"},
+		{big.NewRat(-2, 3), 2, "-0.67"},
+		{big.NewRat(9996, 1000), 3, "10.0"},
+		{big.NewRat(5, 1), 3, "5.00"},
+		{big.NewRat(0, 1), 2, "0.0"},
+	}
+
+	for _, test := range tests {
+		result := calculator.FormatSignificant(test.value, test.figures)
+		if result != test.expected {
+			t.Errorf("FormatSignificant(%s, %d) = %s, want %s", test.value, test.figures, result, test.expected)
+		}
+	}
+}
+
+func TestCountSignificantFigures(t *testing.T) {
+	tests := []struct {
+		literal    string
+		figures    int
+		leastDigit int
+		exact      bool
+	}{
+		{"123", 3, 0, false},
+		{"0.0120", 3, -4, false},
+		{"1200", 2, 2, false},
+		{"1200.", 4, 0, false},
+		{"-4.50", 3, -2, false},
+		{"100.0", 4, -1, false},
+		{"0", 1, 0, false},
+		{"0.00", 1, -2, false},
+		{"0xFF", 0, 0, true},
+		{"0b101", 0, 0, true},
+	}
+
+	for _, test := range tests {
+		figures, leastDigit, exact := calculator.CountSignificantFigures(test.literal)
+		if figures != test.figures || leastDigit != test.leastDigit || exact != test.exact {
+			t.Errorf("CountSignificantFigures(%s) = (%d, %d, %v), want (%d, %d, %v)",
+				test.literal, figures, leastDigit, exact, test.figures, test.leastDigit, test.exact)
+		}
+	}
+}
+
+func TestCalculateSignificant(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   string
+		figures    int
+	}{
+		{"2.50 x 3.1", "7.8", 2},
+		{"12.11 + 18.0 + 1.013", "31.1", 3},
+		{"1.0 - 0.99", "0.0", 0},
+		{"1200 / 7", "200", 1},
+		{"1200 / 7.0", "170", 2},
+		{"100.0 / 3.000", "33.33", 4},
+		{"2.0 x 0x10", "32", 2},
+		{"0x10 + 0x1", "17", 0},
+		{"4.0 / 0b11", "1.3", 2},
+	}
+
+	for _, test := range tests {
+		result, err := calculator.CalculateSignificant(test.expression)
+		if err != nil {
+			t.Errorf("CalculateSignificant(%s) error: %v", test.expression, err)
+			continue
+		}
+		if result.String() != test.expected || result.Figures != test.figures {
+			t.Errorf("CalculateSignificant(%s) = %s (%d figures), want %s (%d figures)",
+				test.expression, result, result.Figures, test.expected, test.figures)
+		}
+	}
+
+	// The exact value is kept alongside the rounded presentation
+	result, err := calculator.CalculateSignificant("2.50 x 3.1")
+	if err != nil {
+		t.Fatalf("CalculateSignificant error: %v", err)
+	}
+	if result.Value.Cmp(big.NewRat(775, 100)) != 0 {
+		t.Errorf("Exact value = %s, want 31/4", result.Value)
+	}
+
+	if _, err := calculator.CalculateSignificant("1.0 / 0"); err == nil {
+		t.Errorf("CalculateSignificant(1.0 / 0) expected error")
+	}
+}
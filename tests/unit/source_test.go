@@ -0,0 +1,70 @@
+package unit
+
+import (
+	"errors"
+	"precise-calc/pkg/calculator"
+	"testing"
+)
+
+func TestFormatSnippet(t *testing.T) {
+	tests := []struct {
+		expression string
+		start      int
+		end        int
+		expected   string
+	}{
+		{"0.1 + + 0.2", 6, 7, "0.1 + + 0.2\n      ^"},
+		{"1 + 0xZZ", 4, 6, "1 + 0xZZ\n    ^~"},
+		{"1 +", 3, 0, "1 +\n   ^"},
+		{"1\t+ @", 4, 5, "1\t+ @\n \t  ^"},
+	}
+
+	for _, test := range tests {
+		result := calculator.FormatSnippet(test.expression, test.start, test.end)
+		if result != test.expected {
+			t.Errorf("FormatSnippet(%q, %d, %d) = %q, want %q", test.expression, test.start, test.end, result, test.expected)
+		}
+	}
+}
+
+func TestCalculateErrorSpans(t *testing.T) {
+	tests := []struct {
+		expression string
+		start      int
+		end        int
+		snippet    string
+	}{
+		{"0.1 + + 0.2", 6, 7, "0.1 + + 0.2\n      ^"},
+		{"  5 / 0", 4, 5, "  5 / 0\n    ^"},
+		{"1 2", 2, 3, "1 2\n  ^"},
+		{"12 + 345 678", 9, 12, "12 + 345 678\n         ^~~"},
+		{"1 + @", 4, 5, "1 + @\n    ^"},
+	}
+
+	for _, test := range tests {
+		_, err := calculator.Calculate(test.expression)
+		var source calculator.SourceError
+		if !errors.As(err, &source) {
+			t.Errorf("Calculate(%q) error = %v, want a SourceError", test.expression, err)
+			continue
+		}
+		start, end := source.Span()
+		if start != test.start || end != test.end {
+			t.Errorf("Calculate(%q) span = %d-%d, want %d-%d", test.expression, start, end, test.start, test.end)
+		}
+		if source.Snippet() != test.snippet {
+			t.Errorf("Calculate(%q) snippet = %q, want %q", test.expression, source.Snippet(), test.snippet)
+		}
+	}
+}
+
+func TestParseErrorContextPopulated(t *testing.T) {
+	_, err := calculator.Calculate("1 + + 2")
+	parseErr, ok := err.(calculator.ParseError)
+	if !ok {
+		t.Fatalf("Calculate error = %v, want ParseError", err)
+	}
+	if parseErr.Context != "1 + + 2\n    ^" {
+		t.Errorf("Context = %q, want snippet", parseErr.Context)
+	}
+}
@@ -0,0 +1,57 @@
+package unit
+
+import (
+	"precise-calc/pkg/calculator"
+	"testing"
+)
+
+func TestSuggestions(t *testing.T) {
+	tests := []struct {
+		expression string
+		suggestion string
+	}{
+		{"3 * 4", "use 'x' for multiplication"},
+		{"3 × 4", "use 'x' for multiplication"},
+		{"3 X 4", "use lowercase 'x' for multiplication"},
+		{"6 ÷ 2", "use '/' for division"},
+		{"5 − 3", "use '-' for subtraction and negative numbers"},
+		{"(1 + 2) x 3", "parentheses are not supported; rely on precedence, where 'x' and '/' bind tighter than '+' and '-'"},
+		{"2 ^ 8", "exponentiation is not supported; write repeated multiplication such as 2 x 2 x 2"},
+		{"1,5 + 2", "use '.' as the decimal separator: 1.5"},
+		{"1,000 + 2", "remove the thousands separator (1000) or use '.' as the decimal separator (1.000)"},
+		{"1,000,000", "remove the thousands separators: 1000000"},
+		{"Ox1F", "use the digit 0, not the letter O, in the 0x prefix"},
+		{"0o17", "octal literals are not supported; use a 0x or 0b prefix"},
+		{"0x1G", "'G' is not a hexadecimal digit; use 0-9 and A-F"},
+		{"FF + 1", "write hexadecimal numbers with a 0x prefix, e.g. 0xFF"},
+		{"1e5", "scientific notation is not supported; write the number out in full"},
+		{"0x + 1", "add hexadecimal digits after 0x, e.g. 0x1F"},
+		{"1 + 0b", "add binary digits after 0b, e.g. 0b101"},
+		{"3 % 2", ""},
+		{"1 + + 2", ""},
+	}
+
+	for _, test := range tests {
+		_, err := calculator.Calculate(test.expression)
+		if err == nil {
+			t.Errorf("Calculate(%q) expected error", test.expression)
+			continue
+		}
+		if s := calculator.SuggestionOf(err); s != test.suggestion {
+			t.Errorf("Calculate(%q) suggestion = %q, want %q", test.expression, s, test.suggestion)
+		}
+	}
+}
+
+func TestSuggestionOnErrorTypes(t *testing.T) {
+	_, err := calculator.Tokenize("2 * 3")
+	invalid, ok := err.(calculator.InvalidCharacterError)
+	if !ok || invalid.Suggestion != "use 'x' for multiplication" {
+		t.Errorf("Tokenize error = %#v, want suggestion on InvalidCharacterError", err)
+	}
+
+	err = calculator.ValidateAll("2 * 3 + 0x", calculator.Options{})
+	if calculator.SuggestionOf(err) != "use 'x' for multiplication" {
+		t.Errorf("SuggestionOf(ErrorList) = %q, want the first suggestion", calculator.SuggestionOf(err))
+	}
+}
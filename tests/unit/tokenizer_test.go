@@ -77,3 +77,42 @@ func TestTokenizeInvalidCharacters(t *testing.T) {
 		}
 	}
 }
+
+func TestTokenizeAttachesNumbers(t *testing.T) {
+	tokens, err := calculator.Tokenize("0xFF + 0b10 x -1.5")
+	if err != nil {
+		t.Fatalf("Tokenize failed: %v", err)
+	}
+
+	expected := []struct {
+		numberType calculator.NumberType
+		value      string
+	}{
+		{calculator.Hexadecimal, "255"},
+		{calculator.Binary, "2"},
+		{calculator.Decimal, "-3/2"},
+	}
+
+	numbers := []*calculator.Number{}
+	for _, token := range tokens {
+		if token.Type == calculator.NumberToken {
+			numbers = append(numbers, token.Number)
+		} else if token.Number != nil {
+			t.Errorf("Operator token %q has a number attached", token.Value)
+		}
+	}
+
+	if len(numbers) != len(expected) {
+		t.Fatalf("Expected %d numbers, got %d", len(expected), len(numbers))
+	}
+	for i, number := range numbers {
+		if number == nil {
+			t.Errorf("Number token %d has no parsed number", i)
+			continue
+		}
+		if number.Type != expected[i].numberType || number.Value.RatString() != expected[i].value {
+			t.Errorf("Number %d = {%v, %s}, want {%v, %s}",
+				i, number.Type, number.Value.RatString(), expected[i].numberType, expected[i].value)
+		}
+	}
+}
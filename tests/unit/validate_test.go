@@ -0,0 +1,103 @@
+package unit
+
+import (
+	"errors"
+	"precise-calc/pkg/calculator"
+	"testing"
+)
+
+func TestValidateAllValid(t *testing.T) {
+	for _, expression := range []string{"1 + 2", "0xFF x 0b101 / 3", "-5 - -5"} {
+		if err := calculator.ValidateAll(expression, calculator.Options{}); err != nil {
+			t.Errorf("ValidateAll(%q) = %v, want nil", expression, err)
+		}
+	}
+}
+
+func TestValidateAllCollectsErrors(t *testing.T) {
+	tests := []struct {
+		expression string
+		positions  []int
+		messages   []string
+	}{
+		{"5 + + 3", []int{4}, []string{"Parse error at position 4: Expected number"}},
+		{"+ 5 +", []int{0, 4}, []string{
+			"Parse error at position 0: Expression must start with a number",
+			"Parse error at position 4: Expression must end with a number",
+		}},
+		{"1 @ 2 # 3", []int{2, 6}, []string{
+			"Invalid character '@' at position 2",
+			"Invalid character '#' at position 6",
+		}},
+		{"0x + 1 2 + 0b", []int{0, 7, 11}, []string{
+			"Parse error at position 0: Invalid number format: 0x",
+			"Parse error at position 7: Expected operator",
+			"Parse error at position 11: Invalid number format: 0b",
+		}},
+		{"1 + + 2 +", []int{4, 8}, []string{
+			"Parse error at position 4: Expected number",
+			"Parse error at position 8: Expression must end with a number",
+		}},
+	}
+
+	for _, test := range tests {
+		err := calculator.ValidateAll(test.expression, calculator.Options{})
+		var list calculator.ErrorList
+		if !errors.As(err, &list) {
+			t.Errorf("ValidateAll(%q) = %v, want ErrorList", test.expression, err)
+			continue
+		}
+		if len(list) != len(test.messages) {
+			t.Errorf("ValidateAll(%q) returned %d errors, want %d: %v", test.expression, len(list), len(test.messages), []error(list))
+			continue
+		}
+		for i, e := range list {
+			source, ok := e.(calculator.SourceError)
+			if !ok {
+				t.Errorf("ValidateAll(%q)[%d] = %v, want a SourceError", test.expression, i, e)
+				continue
+			}
+			if start, _ := source.Span(); start != test.positions[i] || e.Error() != test.messages[i] {
+				t.Errorf("ValidateAll(%q)[%d] = %v at %d, want %s at %d", test.expression, i, e, start, test.messages[i], test.positions[i])
+			}
+			if source.Snippet() == "" {
+				t.Errorf("ValidateAll(%q)[%d] has no snippet", test.expression, i)
+			}
+		}
+	}
+}
+
+func TestValidateAllInvalidCharacterSuppressesStructure(t *testing.T) {
+	// The '*' could be a mistyped operator, so "5 3" is not also reported
+	err := calculator.ValidateAll("5 * 3 +@", calculator.Options{})
+	var list calculator.ErrorList
+	if !errors.As(err, &list) || len(list) != 2 {
+		t.Fatalf("ValidateAll = %v, want two invalid characters", err)
+	}
+	for _, e := range list {
+		if _, ok := e.(calculator.InvalidCharacterError); !ok {
+			t.Errorf("unexpected error %v", e)
+		}
+	}
+}
+
+func TestErrorListUnwrap(t *testing.T) {
+	err := calculator.ValidateAll("1 + @ + 0x", calculator.Options{})
+
+	var invalid calculator.InvalidCharacterError
+	if !errors.As(err, &invalid) || invalid.Character != '@' {
+		t.Errorf("errors.As InvalidCharacterError = %v, want '@'", invalid)
+	}
+	var parseErr calculator.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Position != 8 {
+		t.Errorf("errors.As ParseError = %v, want position 8", parseErr)
+	}
+	if err.Error() != "Invalid character '@' at position 4 (and 1 more error)" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+
+	var empty calculator.EmptyExpressionError
+	if !errors.As(calculator.ValidateAll("  ", calculator.Options{}), &empty) {
+		t.Errorf("ValidateAll of blank input should report EmptyExpressionError")
+	}
+}